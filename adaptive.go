@@ -0,0 +1,65 @@
+package log
+
+import "time"
+
+// adaptive lowers the level to DEBUG while errors are frequent
+type adaptive struct {
+	threshold float64
+	window    time.Duration
+	errors    []time.Time
+	active    bool
+	saved     int
+}
+
+// EnableAdaptiveVerbosity lowers the global level to DEBUG while more than
+// errorRateThreshold ERROR or FATAL records per second were logged over the
+// last window, and restores the previous level afterwards.
+//
+// To avoid flapping the level is only restored once the rate dropped below
+// half the threshold. The rate is checked whenever a record is logged, so
+// the level is restored with the first record after the errors subsided. A
+// threshold of 0 or less disables the feature.
+func (l *QLogger) EnableAdaptiveVerbosity(errorRateThreshold float64, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if a := l.adaptive; a != nil && a.active {
+		SetLevel(a.saved)
+	}
+	l.adaptive = nil
+	if errorRateThreshold > 0 && window > 0 {
+		l.adaptive = &adaptive{threshold: errorRateThreshold, window: window}
+	}
+}
+
+// adapt updates the error rate and switches the level. It must be called
+// with l.mu held.
+func (l *QLogger) adapt(level int, now time.Time) {
+	a := l.adaptive
+	if a == nil {
+		return
+	}
+	if level >= LevelError {
+		a.errors = append(a.errors, now)
+	}
+	i := 0
+	for i < len(a.errors) && now.Sub(a.errors[i]) > a.window {
+		i++
+	}
+	a.errors = a.errors[i:]
+
+	rate := float64(len(a.errors)) / a.window.Seconds()
+	switch {
+	case !a.active && rate > a.threshold:
+		a.active = true
+		a.saved = GetLevel()
+		SetLevel(LevelDebug)
+	case a.active && rate < a.threshold/2:
+		a.active = false
+		SetLevel(a.saved)
+	}
+}
+
+// EnableAdaptiveVerbosity 错误频率超过每秒 errorRateThreshold 条时自动将日志级别降为 DEBUG，恢复后还原
+func EnableAdaptiveVerbosity(errorRateThreshold float64, window time.Duration) {
+	log.EnableAdaptiveVerbosity(errorRateThreshold, window)
+}
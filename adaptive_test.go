@@ -0,0 +1,39 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdaptiveVerbosity(t *testing.T) {
+	setLevel(t, LevelWarn)
+	l, _ := newBufferLogger(t)
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l.SetClock(clock.now)
+	l.EnableAdaptiveVerbosity(2, time.Second)
+	t.Cleanup(func() { l.EnableAdaptiveVerbosity(0, 0) })
+
+	l.Error("failure")
+	l.Error("failure")
+	if GetLevel() != LevelWarn {
+		t.Fatalf("level changed below the threshold: %d", GetLevel())
+	}
+	l.Error("failure")
+	if GetLevel() != LevelDebug {
+		t.Fatalf("level = %d above the threshold, want DEBUG", GetLevel())
+	}
+
+	// Two errors per second is not below half the threshold
+	clock.set(clock.now().Add(1500 * time.Millisecond))
+	l.Error("failure")
+	l.Error("failure")
+	if GetLevel() != LevelDebug {
+		t.Fatalf("level restored within the hysteresis band: %d", GetLevel())
+	}
+
+	clock.set(clock.now().Add(2 * time.Second))
+	l.Warn("calm again")
+	if GetLevel() != LevelWarn {
+		t.Errorf("level = %d after the errors subsided, want WARN", GetLevel())
+	}
+}
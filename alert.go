@@ -0,0 +1,74 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kermitbu/gant-log/colors"
+)
+
+// alertBoxOff disables the alert box
+const alertBoxOff = LevelFatal + 1
+
+// SetAlertBox draws FATAL records inside a bold red box on text outputs, in
+// addition to the regular line, so they stand out right before the exit
+func (l *QLogger) SetAlertBox(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.alertLevel = alertBoxOff
+	if on {
+		l.alertLevel = LevelFatal
+	}
+}
+
+// SetAlertBoxLevel draws the alert box for every record at or above level,
+// e.g. LevelError to box errors as well
+func (l *QLogger) SetAlertBoxLevel(level int) {
+	getLevelTag(level)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.alertLevel = level
+}
+
+// writeAlertBox writes the record caller and message inside a box, colored
+// unless color is false
+func writeAlertBox(w io.Writer, level int, record LogRecord, color bool) error {
+	lines := append([]string{strings.TrimSpace(getLevelTag(level)) + " at " + record.Caller},
+		strings.Split(strings.TrimRight(record.Message, "\n"), "\n")...)
+	width := 0
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n > width {
+			width = n
+		}
+	}
+
+	border := strings.Repeat("═", width+2)
+	box := []string{"╔" + border + "╗"}
+	for _, line := range lines {
+		pad := strings.Repeat(" ", width-utf8.RuneCountInString(line))
+		box = append(box, "║ "+line+pad+" ║")
+	}
+	box = append(box, "╚"+border+"╝")
+
+	var buf bytes.Buffer
+	for _, line := range box {
+		if color {
+			line = colors.RedBold(line)
+		}
+		buf.WriteString(line + EndLine())
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// SetAlertBox 开启后 FATAL 日志会额外以醒目的红色方框输出
+func SetAlertBox(on bool) {
+	log.SetAlertBox(on)
+}
+
+// SetAlertBoxLevel 设置需要以方框输出的最低日志级别，例如 LevelError
+func SetAlertBoxLevel(level int) {
+	log.SetAlertBoxLevel(level)
+}
@@ -0,0 +1,47 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kermitbu/gant-log/colors"
+)
+
+func TestAlertBox(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.SetAlertBoxLevel(LevelError)
+	l.Error("disk gone")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want the record and a 4 line box: %q", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[1], "╔") || !strings.HasPrefix(lines[4], "╚") {
+		t.Errorf("box borders missing: %q", lines[1:])
+	}
+	if !strings.Contains(lines[3], "║ disk gone") {
+		t.Errorf("message line = %q", lines[3])
+	}
+
+	buf.Reset()
+	l.SetAlertBox(false)
+	l.Error("disk gone")
+	if strings.Contains(buf.String(), "╔") {
+		t.Errorf("box drawn while off: %q", buf.String())
+	}
+}
+
+func TestAlertBoxColored(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeAlertBox(&buf, LevelFatal, LogRecord{Caller: "main.go:1", Message: "bye"}, true); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if want := colors.RedBold("║ bye" + strings.Repeat(" ", 15) + " ║"); lines[2] != want {
+		t.Errorf("message line = %q, want %q", lines[2], want)
+	}
+	if !strings.Contains(lines[1], "FATAL at main.go:1") {
+		t.Errorf("header line = %q", lines[1])
+	}
+}
@@ -0,0 +1,90 @@
+package log
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Reasons a CircuitBreaker drops a record
+const (
+	DropCircuitOpen = "circuit_open"
+	DropWriteFailed = "write_failed"
+)
+
+// CircuitBreaker wraps an output that may fail, such as a network sink.
+// After threshold consecutive write failures the circuit opens and records
+// are dropped without touching the output. Every probeInterval one write is
+// let through to probe the output, closing the circuit when it succeeds.
+// The logger writes every record in a single Write, so the threshold and the
+// drop counts are in records and a probe never splits one.
+//
+// Failed and dropped writes are not reported as errors, so a dead output
+// never fails the logger; they are counted under DropWriteFailed and
+// DropCircuitOpen instead.
+type CircuitBreaker struct {
+	mu            sync.Mutex
+	w             io.Writer
+	threshold     int
+	probeInterval time.Duration
+	failures      int
+	open          bool
+	lastProbe     time.Time
+	now           func() time.Time
+}
+
+// NewCircuitBreaker wraps w with a circuit breaker
+func NewCircuitBreaker(w io.Writer, threshold int, probeInterval time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		w:             w,
+		threshold:     threshold,
+		probeInterval: probeInterval,
+		now:           time.Now,
+	}
+}
+
+// Write writes p to the wrapped output unless the circuit is open
+func (b *CircuitBreaker) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.open {
+		now := b.now()
+		if now.Sub(b.lastProbe) < b.probeInterval {
+			drops.add(DropCircuitOpen)
+			return len(p), nil
+		}
+		b.lastProbe = now
+	}
+
+	if _, err := b.w.Write(p); err != nil {
+		b.failures++
+		if b.failures >= b.threshold && !b.open {
+			b.open = true
+			b.lastProbe = b.now()
+		}
+		drops.add(DropWriteFailed)
+		return len(p), nil
+	}
+	b.failures = 0
+	b.open = false
+	return len(p), nil
+}
+
+// Open reports whether the circuit is open
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+// Close closes the wrapped output if it is an io.Closer
+func (b *CircuitBreaker) Close() error {
+	if c, ok := b.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// flakyWriter fails every write while down and records the others
+type flakyWriter struct {
+	down   bool
+	writes []string
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if w.down {
+		return 0, errors.New("connection refused")
+	}
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func TestCircuitBreakerFailAndRecover(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	sink := &flakyWriter{down: true}
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	b := NewCircuitBreaker(sink, 2, time.Second)
+	b.now = clock.now
+	l.AddOutput(b, WithColor(ColorNever))
+
+	failed, open := drops.get(DropWriteFailed), drops.get(DropCircuitOpen)
+	l.Error("lost %d", 1)
+	if b.Open() {
+		t.Fatal("circuit open below the threshold")
+	}
+	l.Error("lost %d", 2)
+	if !b.Open() {
+		t.Fatal("circuit closed after 2 failed records")
+	}
+	l.Error("lost %d", 3)
+	if n := drops.get(DropWriteFailed) - failed; n != 2 {
+		t.Errorf("failed records = %d, want 2", n)
+	}
+	if n := drops.get(DropCircuitOpen) - open; n != 1 {
+		t.Errorf("records dropped while open = %d, want 1", n)
+	}
+
+	sink.down = false
+	l.Error("dropped before the probe")
+	clock.set(clock.now().Add(time.Second))
+	l.Error("probe with fields")
+	l.Error("after recovery")
+	if b.Open() {
+		t.Fatal("circuit still open after a successful probe")
+	}
+	if len(sink.writes) != 2 {
+		t.Fatalf("sink got %d writes, want one per record: %q", len(sink.writes), sink.writes)
+	}
+	for i, want := range []string{"probe with fields", "after recovery"} {
+		if !strings.HasSuffix(sink.writes[i], want+"\n") {
+			t.Errorf("write %d = %q, want the whole %q line", i, sink.writes[i], want)
+		}
+	}
+}
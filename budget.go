@@ -0,0 +1,39 @@
+package log
+
+import "unsafe"
+
+// SetHistoryBudgetBytes bounds the memory retained by the in-memory
+// history, currently the SetRingBuffer records, to about n bytes. The
+// oldest records are evicted first, and the newest one is always kept.
+// The size of a record is estimated from its strings and fields. Zero
+// removes the bound.
+func (l *QLogger) SetHistoryBudgetBytes(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := l.recordRing()
+	r.budget = n
+	r.trim()
+}
+
+// recordSize estimates the memory held by a retained record
+func recordSize(record LogRecord) int {
+	n := int(unsafe.Sizeof(record)) +
+		len(record.ID) + len(record.Message) + len(record.Format) +
+		len(record.Filename) + len(record.Function) + len(record.Caller) +
+		len(record.Component) + len(record.Event) + len(record.Stack)
+	for k, v := range record.Fields {
+		n += len(k) + int(unsafe.Sizeof(v))
+		switch v := v.(type) {
+		case string:
+			n += len(v)
+		case []byte:
+			n += len(v)
+		}
+	}
+	return n
+}
+
+// SetHistoryBudgetBytes 限制内存中保留的历史日志占用的总字节数，超出时先淘汰最早的日志
+func SetHistoryBudgetBytes(n int) {
+	log.SetHistoryBudgetBytes(n)
+}
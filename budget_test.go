@@ -0,0 +1,56 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHistoryBudget(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	l.SetRingBuffer(100)
+	payload := strings.Repeat("x", 1000)
+	size := recordSize(LogRecord{Message: "00 " + payload})
+	l.SetHistoryBudgetBytes(3 * size)
+	for i := 0; i < 10; i++ {
+		l.Error("%02d %s", i, payload)
+	}
+
+	l.mu.Lock()
+	records := l.ring.snapshot()
+	held := l.ring.bytes
+	l.mu.Unlock()
+	if held > 3*size {
+		t.Errorf("ring holds %d bytes over a budget of %d", held, 3*size)
+	}
+	if len(records) == 0 || len(records) >= 10 {
+		t.Fatalf("ring holds %d records", len(records))
+	}
+	for i, record := range records {
+		want := 10 - len(records) + i
+		if !strings.HasPrefix(record.Message, fmt.Sprintf("%02d ", want)) {
+			t.Errorf("record %d = %.10q, want the most recent ones", i, record.Message)
+		}
+	}
+
+	// A budget smaller than one record keeps the newest
+	l.SetHistoryBudgetBytes(1)
+	l.mu.Lock()
+	records = l.ring.snapshot()
+	l.mu.Unlock()
+	if len(records) != 1 || !strings.HasPrefix(records[0].Message, "09 ") {
+		t.Errorf("records = %d, want only the newest", len(records))
+	}
+
+	// Zero removes the bound
+	l.SetHistoryBudgetBytes(0)
+	for i := 0; i < 10; i++ {
+		l.Error("%02d %s", i, payload)
+	}
+	l.mu.Lock()
+	n := l.ring.count
+	l.mu.Unlock()
+	if n != 11 {
+		t.Errorf("unbounded ring holds %d records, want 11", n)
+	}
+}
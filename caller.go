@@ -0,0 +1,93 @@
+package log
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// CallerFormat selects how the caller is rendered in the Caller field
+type CallerFormat int
+
+const (
+	// CallerFileLine renders the caller as handler.go:42
+	CallerFileLine CallerFormat = iota
+	// CallerFuncFileLine renders the caller as handle@handler.go:42
+	CallerFuncFileLine
+)
+
+// SetCallerFormat sets how the caller is rendered in the Caller field
+func (l *QLogger) SetCallerFormat(f CallerFormat) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.callerFormat = f
+}
+
+// formatCaller combines the caller location into a single compact field
+func (l *QLogger) formatCaller(function, file string, line int) string {
+	caller := file + ":" + strconv.Itoa(line)
+	if l.callerFormat == CallerFuncFileLine && function != "" {
+		caller = function + "@" + caller
+	}
+	return caller
+}
+
+// funcName returns the bare function name of pc without its package path,
+// receiver or closure prefix
+func funcName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	return shortFuncName(fn.Name())
+}
+
+// shortFuncName strips the package path, receiver and closure prefix from
+// a fully qualified function name
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// DebugNoCaller logs a DEBUG record without looking up the caller, for hot
+// paths that do not need the file and line
+func (l *QLogger) DebugNoCaller(format string, v ...interface{}) {
+	l.mustLogWith(callOptions{noCaller: true}, LevelDebug, 0, format, v...)
+}
+
+// InfoNoCaller logs an INFO record without looking up the caller
+func (l *QLogger) InfoNoCaller(format string, v ...interface{}) {
+	l.mustLogWith(callOptions{noCaller: true}, LevelInfo, 0, format, v...)
+}
+
+// WarnNoCaller logs a WARN record without looking up the caller
+func (l *QLogger) WarnNoCaller(format string, v ...interface{}) {
+	l.mustLogWith(callOptions{noCaller: true}, LevelWarn, 0, format, v...)
+}
+
+// ErrorNoCaller logs an ERROR record without looking up the caller
+func (l *QLogger) ErrorNoCaller(format string, v ...interface{}) {
+	l.mustLogWith(callOptions{noCaller: true}, LevelError, 0, format, v...)
+}
+
+// DebugNoCaller 与 Debug 相同，但不获取调用位置，适合性能敏感的代码
+func DebugNoCaller(format string, v ...interface{}) {
+	log.DebugNoCaller(format, v...)
+}
+
+// InfoNoCaller 与 Info 相同，但不获取调用位置
+func InfoNoCaller(format string, v ...interface{}) {
+	log.InfoNoCaller(format, v...)
+}
+
+// WarnNoCaller 与 Warn 相同，但不获取调用位置
+func WarnNoCaller(format string, v ...interface{}) {
+	log.WarnNoCaller(format, v...)
+}
+
+// ErrorNoCaller 与 Error 相同，但不获取调用位置
+func ErrorNoCaller(format string, v ...interface{}) {
+	log.ErrorNoCaller(format, v...)
+}
@@ -0,0 +1,71 @@
+package log
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+// lastRecord returns a hook keeping the last record it was given
+func lastRecord(l *QLogger) *LogRecord {
+	var last LogRecord
+	l.AddHook(func(level int, record LogRecord) { last = record })
+	return &last
+}
+
+func TestCallerFormat(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+
+	l.Error("plain")
+	if !strings.HasPrefix(last.Caller, "caller_test.go:") {
+		t.Errorf("file:line caller = %q", last.Caller)
+	}
+
+	l.SetCallerFormat(CallerFuncFileLine)
+	l.Error("compact")
+	if !strings.HasPrefix(last.Caller, "TestCallerFormat@caller_test.go:") {
+		t.Errorf("func@file:line caller = %q", last.Caller)
+	}
+}
+
+func TestShortFuncName(t *testing.T) {
+	for name, want := range map[string]string{
+		"github.com/kermitbu/gant-log.TestX": "TestX",
+		"main.(*server).handle":              "handle",
+		"main.main":                          "main",
+		"noPackage":                          "noPackage",
+	} {
+		if got := shortFuncName(name); got != want {
+			t.Errorf("shortFuncName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNoCaller(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+	l.ErrorNoCaller("hot path")
+	if last.Caller != "" || last.Filename != "" || last.LineNo != 0 {
+		t.Errorf("NoCaller record has caller %q at %s:%d", last.Caller, last.Filename, last.LineNo)
+	}
+}
+
+// BenchmarkCaller compares logging with and without the caller lookup
+func BenchmarkCaller(b *testing.B) {
+	l := New()
+	l.SetOutput(io.Discard)
+	l.SetColorMode(ColorNever)
+	b.Run("caller", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Error("request done")
+		}
+	})
+	b.Run("no-caller", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.ErrorNoCaller("request done")
+		}
+	})
+}
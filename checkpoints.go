@@ -0,0 +1,52 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Checkpoints records named points in time during a request and logs them
+// as one summary line
+type Checkpoints struct {
+	l     *QLogger
+	mu    sync.Mutex
+	start time.Time
+	last  time.Time
+	marks []string
+}
+
+// Trace2 starts recording checkpoints. Unlike Trace, which logs a single
+// event, the timeline is logged once by Done.
+func (l *QLogger) Trace2() *Checkpoints {
+	now := time.Now()
+	return &Checkpoints{l: l, start: now, last: now}
+}
+
+// Mark records the time spent since the previous checkpoint under name
+func (c *Checkpoints) Mark(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	c.marks = append(c.marks, name+"="+FormatDuration(now.Sub(c.last)))
+	c.last = now
+}
+
+// Done logs the checkpoints and the total duration at INFO, e.g.
+// "checkpoints: auth=12ms db=45ms render=8ms total=65ms"
+func (c *Checkpoints) Done() {
+	c.mu.Lock()
+	total := time.Since(c.start)
+	line := strings.Join(append(c.marks, "total="+FormatDuration(total)), " ")
+	c.mu.Unlock()
+
+	fields := map[string]interface{}{
+		"total_ms": total.Milliseconds(),
+	}
+	c.l.mustLogWith(callOptions{fields: fields}, LevelInfo, 2, "checkpoints: %s", line)
+}
+
+// Trace2 开始记录请求中的多个时间点，调用 Done 时输出一行汇总
+func Trace2() *Checkpoints {
+	return log.Trace2()
+}
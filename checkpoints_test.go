@@ -0,0 +1,29 @@
+package log
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestCheckpoints(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+
+	c := l.Trace2()
+	time.Sleep(10 * time.Millisecond)
+	c.Mark("auth")
+	c.Mark("db")
+	time.Sleep(10 * time.Millisecond)
+	c.Mark("render")
+	c.Done()
+
+	want := regexp.MustCompile(`^checkpoints: auth=\d+ms db=\S+ render=\d+ms total=\d+ms$`)
+	if !want.MatchString(last.Message) {
+		t.Errorf("summary = %q", last.Message)
+	}
+	if ms, _ := last.Fields["total_ms"].(int64); ms < 20 {
+		t.Errorf("total_ms = %v, want at least 20", last.Fields["total_ms"])
+	}
+}
@@ -0,0 +1,25 @@
+package log
+
+import "time"
+
+// SetClock sets the function returning the time stamped on the records of
+// this logger, e.g. a simulated or frozen clock. nil restores time.Now.
+func (l *QLogger) SetClock(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.clock = now
+}
+
+// now returns the time of the logger clock. It must be called with l.mu
+// held.
+func (l *QLogger) now() time.Time {
+	if l.clock != nil {
+		return l.clock()
+	}
+	return time.Now()
+}
+
+// SetClock 设置日志时间的来源，例如模拟或固定的时钟，传入 nil 恢复使用 time.Now
+func SetClock(now func() time.Time) {
+	log.SetClock(now)
+}
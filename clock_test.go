@@ -0,0 +1,31 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockPerLogger(t *testing.T) {
+	first, firstBuf := newBufferLogger(t)
+	second, secondBuf := newBufferLogger(t)
+	first.SetClock(func() time.Time { return time.Date(2001, 2, 3, 4, 5, 6, 0, time.Local) })
+	second.SetClock(func() time.Time { return time.Date(2010, 11, 12, 13, 14, 15, 0, time.Local) })
+
+	first.Error("tick")
+	second.Error("tick")
+	if got := firstBuf.String(); !strings.Contains(got, "2001/02/03 04:05:06") {
+		t.Errorf("first logger = %q", got)
+	}
+	if got := secondBuf.String(); !strings.Contains(got, "2010/11/12 13:14:15") {
+		t.Errorf("second logger = %q", got)
+	}
+
+	// Clearing one clock leaves the other frozen
+	first.SetClock(nil)
+	secondBuf.Reset()
+	second.Error("tick")
+	if got := secondBuf.String(); !strings.Contains(got, "2010/11/12 13:14:15") {
+		t.Errorf("second logger after reset = %q", got)
+	}
+}
@@ -146,3 +146,18 @@ func GrayBold(message string) string {
 func MagentaBold(message string) string {
 	return fmt.Sprintf("\x1b[35m%s\x1b[0m", Bold(message))
 }
+
+// DarkGray returns a dark gray string
+func DarkGray(message string) string {
+	return fmt.Sprintf("\x1b[90m%s\x1b[0m", message)
+}
+
+// LightRedBold returns a light red Bold string
+func LightRedBold(message string) string {
+	return fmt.Sprintf("\x1b[91m%s\x1b[0m", Bold(message))
+}
+
+// LightMagentaBold returns a light magenta Bold string
+func LightMagentaBold(message string) string {
+	return fmt.Sprintf("\x1b[95m%s\x1b[0m", Bold(message))
+}
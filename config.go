@@ -0,0 +1,79 @@
+package log
+
+import "io"
+
+// Config describes the logger setup applied by Configure. Zero values select
+// the defaults. Settings without a field here, such as hooks, are left as
+// they are.
+type Config struct {
+	// Levels is a level policy as accepted by SetLevelsFromString, e.g.
+	// "info" or "default=info,db=debug". Empty keeps the current levels.
+	Levels string
+
+	// Output is the primary output, os.Stdout when nil
+	Output io.Writer
+	// ExtraOutputs receive every record in addition to Output
+	ExtraOutputs []io.Writer
+
+	Format    Format
+	ColorMode ColorMode
+
+	// TimeFormat is the timestamp layout of text lines. Like Prefix and
+	// Levels it is shared by all loggers.
+	TimeFormat string
+	// Prefix is the banner starting every text line
+	Prefix string
+
+	CallerFormat  CallerFormat
+	StrictFormat  bool
+	SingleLine    bool
+	CloseSummary  bool
+	RecordPooling bool
+}
+
+// Configure applies cfg in one reconfiguration: records logged concurrently
+// see either the old or the new setup for everything but the levels, the
+// time format and the prefix, which are shared by all loggers. The
+// outputs previously added, and the files the logger opened, are replaced
+// and closed. Nothing is applied if cfg.Levels is invalid.
+func (l *QLogger) Configure(cfg Config) error {
+	if cfg.Levels != "" {
+		if err := SetLevelsFromString(cfg.Levels); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, c := range l.owned {
+		c.Close()
+	}
+	l.owned = nil
+
+	l.primary = newSink(cfg.Output, WithFormat(cfg.Format), WithColor(cfg.ColorMode), WithSingleLine(cfg.SingleLine))
+	l.sinks = []*sink{l.primary}
+	for _, w := range cfg.ExtraOutputs {
+		l.sinks = append(l.sinks, newSink(w, WithFormat(cfg.Format), WithColor(cfg.ColorMode), WithSingleLine(cfg.SingleLine)))
+	}
+
+	if cfg.TimeFormat == "" {
+		cfg.TimeFormat = defaultTimeFormat
+	}
+	timeFormat.Store(cfg.TimeFormat)
+	if cfg.Prefix == "" {
+		cfg.Prefix = defaultPrefix
+	}
+	bannerPrefix.Store(cfg.Prefix)
+
+	l.callerFormat = cfg.CallerFormat
+	l.strictFormat = cfg.StrictFormat
+	l.closeSummary = cfg.CloseSummary
+	l.pooling = cfg.RecordPooling
+	return nil
+}
+
+// Configure 使用一个结构体一次性完成日志的全部配置
+func Configure(cfg Config) error {
+	return log.Configure(cfg)
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+)
+
+// restoreBanner restores the default prefix and time format after the test
+func restoreBanner(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		SetPrefix(defaultPrefix)
+		SetTimeFormat(defaultTimeFormat)
+	})
+}
+
+func TestConfigure(t *testing.T) {
+	setLevel(t, LevelDebug)
+	resetComponentLevels(t)
+	restoreBanner(t)
+
+	l := New()
+	var out, extra bytes.Buffer
+	err := l.Configure(Config{
+		Levels:       "warn,db=debug",
+		Output:       &out,
+		ExtraOutputs: []io.Writer{&extra},
+		ColorMode:    ColorNever,
+		TimeFormat:   "15:04",
+		Prefix:       "[svc]",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != LevelWarn || !levelEnabled("db", LevelDebug) {
+		t.Errorf("levels not applied: global %d", GetLevel())
+	}
+
+	l.Info("filtered")
+	l.Warn("kept")
+	want := regexp.MustCompile(`^\[svc\] \d\d:\d\d WARN +▶ +kept\n$`)
+	if !want.MatchString(out.String()) {
+		t.Errorf("output = %q", out.String())
+	}
+	if extra.String() != out.String() {
+		t.Errorf("extra output = %q, want %q", extra.String(), out.String())
+	}
+
+	out.Reset()
+	if err := l.Configure(Config{Output: &out, Format: FormatJSON}); err != nil {
+		t.Fatal(err)
+	}
+	l.Warn("json")
+	var obj map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &obj); err != nil || obj["msg"] != "json" {
+		t.Errorf("reconfigured output = %q", out.String())
+	}
+}
+
+func TestConfigureInvalidLevels(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, buf := newBufferLogger(t)
+	var other bytes.Buffer
+	if err := l.Configure(Config{Levels: "loud", Output: &other}); err == nil {
+		t.Fatal("no error for an invalid level policy")
+	}
+	l.Error("still here")
+	if other.Len() != 0 || buf.Len() == 0 {
+		t.Errorf("configuration applied despite the error")
+	}
+}
+
+func TestBannerSharedAcrossLoggers(t *testing.T) {
+	restoreBanner(t)
+	l, buf := newBufferLogger(t)
+	other := New()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			other.SetPrefix("[svc]")
+			other.SetTimeFormat(time.RFC3339)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		l.Error("concurrent")
+	}
+	wg.Wait()
+
+	buf.Reset()
+	l.Error("after")
+	if got := buf.String(); !regexp.MustCompile(`^\[svc\] \d{4}-\d\d-\d\dT`).MatchString(got) {
+		t.Errorf("banner set on another logger not used: %q", got)
+	}
+}
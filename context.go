@@ -0,0 +1,136 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+type scopeKey struct{}
+
+type sampledKey struct{}
+
+type fieldsKey struct{}
+
+// ContextWithFields returns a copy of ctx carrying fields, merged over the
+// fields ctx already carries. Records logged with the context, e.g. by
+// InfoContext, include them unless the call passes a field of the same name.
+func ContextWithFields(ctx context.Context, fields map[string]interface{}) context.Context {
+	parent, _ := ctx.Value(fieldsKey{}).(map[string]interface{})
+	merged := make(map[string]interface{}, len(parent)+len(fields))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithTraceSampled marks the trace of ctx as sampled or not. DEBUG records
+// logged with a sampled context are emitted regardless of the level, and
+// suppressed for an unsampled one.
+func WithTraceSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, sampledKey{}, sampled)
+}
+
+// traceSampled returns the sampling decision stored in ctx, if any
+func traceSampled(ctx context.Context) (sampled, ok bool) {
+	if ctx == nil {
+		return false, false
+	}
+	sampled, ok = ctx.Value(sampledKey{}).(bool)
+	return sampled, ok
+}
+
+// timerScope is a named timer stored in a context. Scopes nest through
+// parent.
+type timerScope struct {
+	name   string
+	start  time.Time
+	parent *timerScope
+}
+
+// path returns the names of the scope and its parents, outermost first
+func (s *timerScope) path() string {
+	var names []string
+	for ; s != nil; s = s.parent {
+		names = append([]string{s.name}, names...)
+	}
+	return strings.Join(names, "/")
+}
+
+// StartScope starts a named timer scope nested in any scope of ctx. Records
+// logged with the returned context carry the scope path, e.g.
+// "request/query", and the time elapsed in the innermost scope. The
+// returned function logs the duration of the scope.
+func (l *QLogger) StartScope(ctx context.Context, name string) (context.Context, func()) {
+	parent, _ := ctx.Value(scopeKey{}).(*timerScope)
+	scope := &timerScope{name: name, start: time.Now(), parent: parent}
+	ctx = context.WithValue(ctx, scopeKey{}, scope)
+	return ctx, func() {
+		l.mustLogWith(callOptions{ctx: ctx}, LevelInfo, 2, "%s took %s", scope.path(), FormatDuration(time.Since(scope.start)))
+	}
+}
+
+// addContextFields adds the annotations carried by ctx
+func addContextFields(fields *fieldBuilder, ctx context.Context) {
+	if ctx == nil {
+		return
+	}
+	carried, _ := ctx.Value(fieldsKey{}).(map[string]interface{})
+	for k, v := range carried {
+		if _, ok := fields.base[k]; !ok {
+			fields.set(k, v)
+		}
+	}
+	if scope, _ := ctx.Value(scopeKey{}).(*timerScope); scope != nil {
+		fields.set("scope", scope.path())
+		fields.set("scope_elapsed_ms", time.Since(scope.start).Milliseconds())
+	}
+}
+
+// DebugContext logs a DEBUG record annotated from ctx
+func (l *QLogger) DebugContext(ctx context.Context, format string, v ...interface{}) {
+	l.mustLogWith(callOptions{ctx: ctx}, LevelDebug, 2, format, v...)
+}
+
+// InfoContext logs an INFO record annotated from ctx
+func (l *QLogger) InfoContext(ctx context.Context, format string, v ...interface{}) {
+	l.mustLogWith(callOptions{ctx: ctx}, LevelInfo, 2, format, v...)
+}
+
+// WarnContext logs a WARN record annotated from ctx
+func (l *QLogger) WarnContext(ctx context.Context, format string, v ...interface{}) {
+	l.mustLogWith(callOptions{ctx: ctx}, LevelWarn, 2, format, v...)
+}
+
+// ErrorContext logs an ERROR record annotated from ctx
+func (l *QLogger) ErrorContext(ctx context.Context, format string, v ...interface{}) {
+	l.mustLogWith(callOptions{ctx: ctx}, LevelError, 2, format, v...)
+}
+
+// StartScope 开始一个可嵌套的计时范围，使用返回的 ctx 输出的日志会带上范围名称和已耗时间
+func StartScope(ctx context.Context, name string) (context.Context, func()) {
+	return log.StartScope(ctx, name)
+}
+
+// DebugContext 与 Debug 相同，并附加 ctx 中的信息
+func DebugContext(ctx context.Context, format string, v ...interface{}) {
+	log.mustLogWith(callOptions{ctx: ctx}, LevelDebug, 2, format, v...)
+}
+
+// InfoContext 与 Info 相同，并附加 ctx 中的信息
+func InfoContext(ctx context.Context, format string, v ...interface{}) {
+	log.mustLogWith(callOptions{ctx: ctx}, LevelInfo, 2, format, v...)
+}
+
+// WarnContext 与 Warn 相同，并附加 ctx 中的信息
+func WarnContext(ctx context.Context, format string, v ...interface{}) {
+	log.mustLogWith(callOptions{ctx: ctx}, LevelWarn, 2, format, v...)
+}
+
+// ErrorContext 与 Error 相同，并附加 ctx 中的信息
+func ErrorContext(ctx context.Context, format string, v ...interface{}) {
+	log.mustLogWith(callOptions{ctx: ctx}, LevelError, 2, format, v...)
+}
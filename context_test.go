@@ -0,0 +1,77 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartScope(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+
+	ctx, endRequest := l.StartScope(context.Background(), "request")
+	ctx, endQuery := l.StartScope(ctx, "query")
+	time.Sleep(5 * time.Millisecond)
+	l.InfoContext(ctx, "rows fetched")
+	if last.Fields["scope"] != "request/query" {
+		t.Errorf("scope = %v", last.Fields["scope"])
+	}
+	if ms, _ := last.Fields["scope_elapsed_ms"].(int64); ms < 5 {
+		t.Errorf("scope_elapsed_ms = %v, want at least 5", last.Fields["scope_elapsed_ms"])
+	}
+
+	endQuery()
+	if !strings.HasPrefix(last.Message, "request/query took ") {
+		t.Errorf("end of query logged %q", last.Message)
+	}
+	endRequest()
+	if last.Fields["scope"] != "request" {
+		t.Errorf("end of request scope = %v", last.Fields["scope"])
+	}
+}
+
+func TestTraceSampledDebug(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, buf := newBufferLogger(t)
+
+	l.DebugContext(WithTraceSampled(context.Background(), true), "sampled detail")
+	l.DebugContext(WithTraceSampled(context.Background(), false), "unsampled detail")
+	l.DebugContext(context.Background(), "plain detail")
+
+	got := buf.String()
+	if !strings.Contains(got, "sampled detail") {
+		t.Errorf("sampled DEBUG record suppressed: %q", got)
+	}
+	if strings.Contains(got, "unsampled detail") || strings.Contains(got, "plain detail") {
+		t.Errorf("DEBUG record below the level emitted: %q", got)
+	}
+
+	setLevel(t, LevelDebug)
+	buf.Reset()
+	l.DebugContext(WithTraceSampled(context.Background(), false), "unsampled detail")
+	if buf.Len() != 0 {
+		t.Errorf("unsampled DEBUG record emitted: %q", buf.String())
+	}
+}
+
+func TestContextWithFields(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+
+	ctx := ContextWithFields(context.Background(), map[string]interface{}{"request_id": "r-1", "user": "bob"})
+	ctx = ContextWithFields(ctx, map[string]interface{}{"user": "alice"})
+	l.ErrorContext(ctx, "denied")
+	if last.Fields["request_id"] != "r-1" || last.Fields["user"] != "alice" {
+		t.Errorf("fields = %v", last.Fields)
+	}
+
+	// Fields passed by the call win over the carried ones
+	opts := callOptions{ctx: ctx, fields: map[string]interface{}{"user": "carol"}}
+	l.mustLogWith(opts, LevelError, 1, "denied")
+	if last.Fields["user"] != "carol" || last.Fields["request_id"] != "r-1" {
+		t.Errorf("fields = %v", last.Fields)
+	}
+}
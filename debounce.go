@@ -0,0 +1,77 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// debounceMaxKeys bounds the number of distinct messages Debounce tracks
+const debounceMaxKeys = 1024
+
+// debounceKey identifies repetitions of a record
+type debounceKey struct {
+	text  string
+	level int
+}
+
+// Debounce returns a hook passing a record to h at most once per message and
+// level per interval, e.g. to keep an external alerting hook from paging for
+// every repetition of the same error. Records are still written to the
+// outputs every time.
+//
+// At most 1024 messages are tracked. Once that many fired within the last
+// interval, records with new messages are passed to h without being
+// debounced.
+//
+//	log.AddHook(log.Debounce(pageOnCall, time.Minute))
+func Debounce(h Hook, interval time.Duration) Hook {
+	return debounce(h, interval, func(record LogRecord) string {
+		return record.Message
+	})
+}
+
+// DebounceByFormat is like Debounce but tells records apart by their format
+// string, so "user 1 failed" and "user 2 failed" count as the same alert.
+// Records without a format, such as the ones the logger writes itself, are
+// told apart by message.
+func DebounceByFormat(h Hook, interval time.Duration) Hook {
+	return debounce(h, interval, func(record LogRecord) string {
+		if record.Format == "" {
+			return record.Message
+		}
+		return record.Format
+	})
+}
+
+func debounce(h Hook, interval time.Duration, text func(LogRecord) string) Hook {
+	var (
+		mu        sync.Mutex
+		last      = make(map[debounceKey]time.Time)
+		lastSweep time.Time
+	)
+	return func(level int, record LogRecord) {
+		key := debounceKey{text: text(record), level: level}
+
+		mu.Lock()
+		prev, seen := last[key]
+		fire := !seen || record.Time.Sub(prev) >= interval
+		if fire && !seen && len(last) >= debounceMaxKeys && record.Time.Sub(lastSweep) >= interval {
+			// Sweeping at most once per interval keeps a full table from
+			// being scanned on every record
+			lastSweep = record.Time
+			for k, t := range last {
+				if record.Time.Sub(t) >= interval {
+					delete(last, k)
+				}
+			}
+		}
+		if fire && (seen || len(last) < debounceMaxKeys) {
+			last[key] = record.Time
+		}
+		mu.Unlock()
+
+		if fire {
+			h(level, record)
+		}
+	}
+}
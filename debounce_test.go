@@ -0,0 +1,72 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDebounce(t *testing.T) {
+	var fired []string
+	h := Debounce(func(level int, record LogRecord) {
+		fired = append(fired, record.Message)
+	}, time.Minute)
+
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	send := func(offset time.Duration, level int, format string, v ...interface{}) {
+		h(level, LogRecord{Time: start.Add(offset), Format: format, Message: fmt.Sprintf(format, v...)})
+	}
+	for i := 0; i < 100; i++ {
+		send(time.Duration(i)*100*time.Millisecond, LevelError, "user %d failed", 1)
+	}
+	send(time.Second, LevelError, "user %d failed", 2)
+	send(2*time.Second, LevelWarn, "user %d failed", 1)
+	send(time.Minute, LevelError, "user %d failed", 1)
+	send(time.Minute+time.Second, LevelError, "user %d failed", 1)
+
+	want := []string{"user 1 failed", "user 2 failed", "user 1 failed", "user 1 failed"}
+	if fmt.Sprint(fired) != fmt.Sprint(want) {
+		t.Errorf("fired %q, want %q", fired, want)
+	}
+}
+
+func TestDebounceByFormat(t *testing.T) {
+	var fired []string
+	h := DebounceByFormat(func(level int, record LogRecord) {
+		fired = append(fired, record.Message)
+	}, time.Minute)
+
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	send := func(offset time.Duration, level int, format string, v ...interface{}) {
+		h(level, LogRecord{Time: start.Add(offset), Format: format, Message: fmt.Sprintf(format, v...)})
+	}
+	send(0, LevelError, "user %d failed", 1)
+	send(time.Second, LevelError, "user %d failed", 2)
+	send(2*time.Second, LevelWarn, "user %d failed", 3)
+	send(3*time.Second, LevelError, "disk full")
+	send(time.Minute, LevelError, "user %d failed", 4)
+
+	want := []string{"user 1 failed", "user 3 failed", "disk full", "user 4 failed"}
+	if fmt.Sprint(fired) != fmt.Sprint(want) {
+		t.Errorf("fired %q, want %q", fired, want)
+	}
+}
+
+func TestDebounceBounded(t *testing.T) {
+	fired := 0
+	h := Debounce(func(level int, record LogRecord) { fired++ }, time.Minute)
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3*debounceMaxKeys; i++ {
+		h(LevelError, LogRecord{Time: now, Message: fmt.Sprintf("message %d", i)})
+	}
+	if fired != 3*debounceMaxKeys {
+		t.Errorf("fired %d times, want every distinct message", fired)
+	}
+
+	// The first messages are still debounced
+	h(LevelError, LogRecord{Time: now, Message: "message 0"})
+	if fired != 3*debounceMaxKeys {
+		t.Error("tracked message fired again within the interval")
+	}
+}
@@ -0,0 +1,192 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var formatNames = [...]string{
+	FormatText:  "text",
+	FormatJSON:  "json",
+	FormatAuto:  "auto",
+	FormatTable: "table",
+}
+
+var colorModeNames = [...]string{
+	ColorAlways: "always",
+	ColorAuto:   "auto",
+	ColorNever:  "never",
+}
+
+var callerFormatNames = [...]string{
+	CallerFileLine:     "file:line",
+	CallerFuncFileLine: "func@file:line",
+}
+
+var overflowPolicyNames = [...]string{
+	OverflowDrop:  "drop",
+	OverflowBlock: "block",
+}
+
+var themeNames = [...]string{
+	ThemeDefault:   "default",
+	ThemeIntensity: "intensity",
+}
+
+// String returns the name of the format
+func (f Format) String() string {
+	return enumName(formatNames[:], int(f), "Format")
+}
+
+// String returns the name of the color mode
+func (m ColorMode) String() string {
+	return enumName(colorModeNames[:], int(m), "ColorMode")
+}
+
+// String returns the name of the caller format
+func (f CallerFormat) String() string {
+	return enumName(callerFormatNames[:], int(f), "CallerFormat")
+}
+
+// String returns the name of the overflow policy
+func (p OverflowPolicy) String() string {
+	return enumName(overflowPolicyNames[:], int(p), "OverflowPolicy")
+}
+
+// String returns the name of the theme
+func (t Theme) String() string {
+	return enumName(themeNames[:], int(t), "Theme")
+}
+
+// enumName returns names[v], or typ(v) for a value without a name
+func enumName(names []string, v int, typ string) string {
+	if v < 0 || v >= len(names) {
+		return fmt.Sprintf("%s(%d)", typ, v)
+	}
+	return names[v]
+}
+
+// DiagnosticDump writes the complete logger state to w: levels, outputs,
+// hooks, static fields, rendering options and the template source. It is
+// meant for debugging logging that behaves unexpectedly.
+func (l *QLogger) DiagnosticDump(w io.Writer) {
+	l.DiagnosticDumpContext(context.Background(), w)
+}
+
+// DiagnosticDumpContext is like DiagnosticDump and also lists the fields
+// that records logged with ctx would carry
+func (l *QLogger) DiagnosticDumpContext(ctx context.Context, w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p := func(format string, v ...interface{}) {
+		fmt.Fprintf(w, format+"\n", v...)
+	}
+
+	p("level: %s", levelName(GetLevel()))
+	componentMu.RLock()
+	names := make([]string, 0, len(componentLevels))
+	for name := range componentLevels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p("component level: %s=%s", name, levelName(componentLevels[name]))
+	}
+	componentMu.RUnlock()
+
+	for i, s := range l.sinks {
+		role := "output"
+		if s == l.primary {
+			role = "primary output"
+		}
+		if s.name != "" {
+			role += " " + strconv.Quote(s.name)
+		}
+		p("%s %d: %T format=%s effective=%s color=%s level=%s filter=%t single_line=%t owned=%t",
+			role, i, s.writer, s.format, s.effectiveFormat(), s.color, levelName(s.level),
+			s.filter != nil, s.singleLine, l.owns(s.writer))
+	}
+
+	for i, h := range l.hooks {
+		p("hook %d: %s", i, hookName(h))
+	}
+	if l.hookPool != nil {
+		p("hooks: async queue=%d/%d policy=%s", len(l.hookPool.queue), cap(l.hookPool.queue), l.hookPool.policy)
+	} else {
+		p("hooks: sync")
+	}
+
+	for _, k := range sortedKeys(l.staticFields) {
+		p("static field: %s=%s", k, formatValue(l.staticFields[k]))
+	}
+	if ctx != nil {
+		carried, _ := ctx.Value(fieldsKey{}).(map[string]interface{})
+		for _, k := range sortedKeys(carried) {
+			p("context field: %s=%s", k, formatValue(carried[k]))
+		}
+	}
+
+	if r := l.ring; r != nil {
+		p("ring buffer: size=%d held=%d bytes=%d budget=%d followers=%d",
+			len(r.entries), r.count, r.bytes, r.budget, len(r.tails))
+	}
+	if names, _ := fieldNames.Load().(map[string]string); len(names) > 0 {
+		p("field names: %v", names)
+	}
+	p("format interning: %t", l.interning)
+	p("caller format: %s", l.callerFormat)
+	p("strict format: %t", l.strictFormat)
+	p("theme: %s", currentTheme())
+	p("separator glyph: %q", separator())
+	p("line separator: %q", l.lineSeparator)
+	p("continuation indent: %q", l.continuationIndent)
+	if l.alertLevel <= LevelFatal {
+		p("alert box level: %s", levelName(l.alertLevel))
+	}
+	if e := l.escalation; e != nil {
+		p("escalation: %d %s within %s to %s", e.count, levelName(e.from), e.window, levelName(e.to))
+	}
+	if a := l.adaptive; a != nil {
+		p("adaptive verbosity: threshold=%g/s window=%s active=%t", a.threshold, a.window, a.active)
+	}
+	if c := l.callerLimit; c != nil {
+		p("caller rate limit: %d per %s, %d sites", c.limit, c.window, len(c.sites))
+	}
+	if l.autoStackLevel <= LevelFatal {
+		p("auto stack level: %s", levelName(l.autoStackLevel))
+	}
+	if l.leakBaseline >= 0 {
+		p("goroutine leak baseline: %d", l.leakBaseline)
+	}
+	p("debug mode: %t", debugMode)
+	p("template: %s", logRecordTemplate.Root.String())
+}
+
+func levelName(level int) string {
+	return strings.TrimSpace(getLevelTag(level))
+}
+
+// hookName returns the function name of a hook
+func hookName(h Hook) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(h).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "???"
+}
+
+// DiagnosticDump 将日志的全部配置状态写到 w，用于排查日志行为异常的问题
+func DiagnosticDump(w io.Writer) {
+	log.DiagnosticDump(w)
+}
+
+// DiagnosticDumpContext 与 DiagnosticDump 相同，并列出 ctx 中携带的字段
+func DiagnosticDumpContext(ctx context.Context, w io.Writer) {
+	log.DiagnosticDumpContext(ctx, w)
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func dumpTestHook(level int, record LogRecord) {}
+
+func TestDiagnosticDump(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	l.AddHook(dumpTestHook)
+	l.SetStaticFields(map[string]interface{}{"version": "1.4.2", "region": "eu west"})
+	l.SetCallerFormat(CallerFuncFileLine)
+	l.SetAsyncHooks(1, 4, OverflowBlock)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+
+	ctx := ContextWithFields(context.Background(), map[string]interface{}{"request_id": "r-17"})
+	var buf bytes.Buffer
+	l.DiagnosticDumpContext(ctx, &buf)
+	dump := buf.String()
+	for _, want := range []string{
+		"hook 0: github.com/kermitbu/gant-log.dumpTestHook\n",
+		"static field: region=\"eu west\"\nstatic field: version=1.4.2\n",
+		"context field: request_id=r-17\n",
+		"primary output 0: *bytes.Buffer format=text effective=text color=never level=DEBUG",
+		"hooks: async queue=0/4 policy=block",
+		"caller format: func@file:line",
+		"theme: default",
+		"template: ",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("dump lacks %q:\n%s", want, dump)
+		}
+	}
+}
+
+func TestEnumString(t *testing.T) {
+	for _, c := range []struct {
+		got, want string
+	}{
+		{FormatTable.String(), "table"},
+		{ColorAuto.String(), "auto"},
+		{OverflowDrop.String(), "drop"},
+		{ThemeIntensity.String(), "intensity"},
+		{CallerFormat(7).String(), "CallerFormat(7)"},
+	} {
+		if c.got != c.want {
+			t.Errorf("String() = %q, want %q", c.got, c.want)
+		}
+	}
+}
+
+func TestStaticFields(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+	l.SetStaticFields(map[string]interface{}{"version": "1.4.2", "region": "eu"})
+
+	ctx := ContextWithFields(context.Background(), map[string]interface{}{"region": "us"})
+	l.ErrorContext(ctx, "static")
+	if last.Fields["version"] != "1.4.2" || last.Fields["region"] != "us" {
+		t.Errorf("fields = %v, want the static version and the context region", last.Fields)
+	}
+
+	l.SetStaticFields(nil)
+	l.Error("none")
+	if len(last.Fields) != 0 {
+		t.Errorf("fields after clearing = %v", last.Fields)
+	}
+}
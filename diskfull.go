@@ -0,0 +1,100 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+)
+
+// DropDiskFull counts the lines a FileWriter dropped while the disk was full
+const DropDiskFull = "disk_full"
+
+const (
+	defaultDiskFullProbe = time.Second
+	defaultDiskFullWarn  = time.Minute
+)
+
+// diskFull is the state of a FileWriter that hit ENOSPC
+type diskFull struct {
+	active    bool
+	since     time.Time
+	lastProbe time.Time
+	lastWarn  time.Time
+	dropped   uint64
+}
+
+// SetDiskFullRetry sets how a FileWriter degrades when the disk is full.
+// While full, lines are dropped and counted under DropDiskFull; a write is
+// attempted every probe interval to detect free space again, and a WARN
+// line goes to stderr every warn interval. The defaults are one second and
+// one minute.
+func (w *FileWriter) SetDiskFullRetry(probe, warn time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.diskFullProbe = probe
+	w.diskFullWarn = warn
+}
+
+// DiskFull reports whether the writer is dropping lines because the disk is
+// full
+func (w *FileWriter) DiskFull() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.full.active
+}
+
+// skipWhileFull reports whether the record must be dropped without trying
+// to write it. It must be called with w.mu held.
+func (w *FileWriter) skipWhileFull() bool {
+	if !w.full.active {
+		return false
+	}
+	now := w.now()
+	if now.Sub(w.full.lastProbe) >= w.diskFullProbe {
+		w.full.lastProbe = now
+		return false
+	}
+	w.dropFull(now)
+	return true
+}
+
+// writeFailed handles a failed write, entering the disk full mode on
+// ENOSPC. It reports whether the error was handled. It must be called with
+// w.mu held.
+func (w *FileWriter) writeFailed(err error) bool {
+	if !errors.Is(err, syscall.ENOSPC) {
+		return false
+	}
+	now := w.now()
+	if !w.full.active {
+		w.full = diskFull{active: true, since: now, lastProbe: now}
+	}
+	w.dropFull(now)
+	return true
+}
+
+// writeSucceeded leaves the disk full mode. It must be called with w.mu
+// held.
+func (w *FileWriter) writeSucceeded() {
+	if !w.full.active {
+		return
+	}
+	fmt.Fprintf(w.stderr, "%s %s %s logger: %s is writable again after %s, %d lines dropped\n",
+		prefix(), stamp(w.now()), getLevelTag(LevelWarn), w.path,
+		FormatDuration(w.now().Sub(w.full.since)), w.full.dropped)
+	w.full = diskFull{}
+}
+
+// dropFull counts the dropped write, which holds one record, and warns when
+// the warn interval elapsed. It must be called with w.mu held.
+func (w *FileWriter) dropFull(now time.Time) {
+	w.full.dropped++
+	drops.add(DropDiskFull)
+	if !w.full.lastWarn.IsZero() && now.Sub(w.full.lastWarn) < w.diskFullWarn {
+		return
+	}
+	w.full.lastWarn = now
+	fmt.Fprintf(w.stderr, "%s %s %s logger: disk full writing %s, %d lines dropped so far\n",
+		prefix(), stamp(now), getLevelTag(LevelWarn), w.path, w.full.dropped)
+}
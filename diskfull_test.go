@@ -0,0 +1,82 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileWriterDiskFull(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("no /dev/full:", err)
+	}
+	w, err := NewFileWriter("/dev/full")
+	if err != nil {
+		t.Skip(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	var stderr bytes.Buffer
+	w.stderr = &stderr
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)}
+	w.SetClock(clock.now)
+	w.SetDiskFullRetry(time.Second, time.Minute)
+	before := drops.get(DropDiskFull)
+
+	write := func(line string) {
+		t.Helper()
+		if n, err := w.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v", line, n, err)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		write("full\n")
+	}
+	if !w.DiskFull() {
+		t.Fatal("writer not in disk full mode after ENOSPC")
+	}
+	if got := drops.get(DropDiskFull) - before; got != 10 {
+		t.Errorf("dropped %d records, want 10", got)
+	}
+	if n := strings.Count(stderr.String(), "disk full writing /dev/full"); n != 1 {
+		t.Errorf("%d warnings in the first interval:\n%s", n, stderr.String())
+	}
+
+	// A probe after the probe interval fails again; the warning repeats
+	// only once the warn interval elapsed
+	clock.set(clock.now().Add(2 * time.Second))
+	write("probe\n")
+	if !w.DiskFull() || strings.Count(stderr.String(), "disk full writing") != 1 {
+		t.Errorf("after a failed probe: full=%v stderr=%q", w.DiskFull(), stderr.String())
+	}
+	clock.set(clock.now().Add(time.Minute))
+	write("probe\n")
+	if got := stderr.String(); strings.Count(got, "disk full writing") != 2 || !strings.Contains(got, "12 lines dropped so far") {
+		t.Errorf("stderr = %q", got)
+	}
+
+	// Space is back: the next probe writes and leaves the mode
+	path := filepath.Join(t.TempDir(), "app.log")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.mu.Lock()
+	w.file.Close()
+	w.file = file
+	w.mu.Unlock()
+	write("dropped before the probe\n")
+	clock.set(clock.now().Add(2 * time.Second))
+	write("back\n")
+	if w.DiskFull() {
+		t.Error("writer still in disk full mode after a successful write")
+	}
+	if got := stderr.String(); !strings.Contains(got, "is writable again after") || !strings.Contains(got, "13 lines dropped\n") {
+		t.Errorf("stderr = %q", got)
+	}
+	if got := readFile(t, path); got != "back\n" {
+		t.Errorf("file = %q", got)
+	}
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// Reasons a record is dropped, as reported by Drops and the drop report
+const (
+	DropAsyncFull = "async_full"
+)
+
+// dropCounter counts the records dropped anywhere in the logger by reason
+type dropCounter struct {
+	mu       sync.Mutex
+	total    map[string]uint64
+	reported map[string]uint64
+}
+
+var drops = dropCounter{
+	total:    map[string]uint64{},
+	reported: map[string]uint64{},
+}
+
+func (c *dropCounter) add(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.total[reason]++
+}
+
+func (c *dropCounter) get(reason string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.total[reason]
+}
+
+// snapshot returns a copy of the totals
+func (c *dropCounter) snapshot() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[string]uint64, len(c.total))
+	for reason, n := range c.total {
+		counts[reason] = n
+	}
+	return counts
+}
+
+// sinceReport returns the counts added since the previous call
+func (c *dropCounter) sinceReport() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var counts map[string]uint64
+	for reason, n := range c.total {
+		if d := n - c.reported[reason]; d > 0 {
+			if counts == nil {
+				counts = map[string]uint64{}
+			}
+			counts[reason] = d
+			c.reported[reason] = n
+		}
+	}
+	return counts
+}
+
+// Drops returns how many records were dropped so far by reason
+func Drops() map[string]uint64 {
+	return drops.snapshot()
+}
+
+// SetDropReportInterval logs a WARN accounting record every interval
+// summarizing the records dropped since the previous report by reason, e.g.
+// {"async_full":5}. Nothing is logged for an interval without drops. An
+// interval of 0 stops the reports.
+func (l *QLogger) SetDropReportInterval(interval time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.dropReport != nil {
+		close(l.dropReport)
+		l.dropReport = nil
+	}
+	if interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	l.dropReport = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.reportDrops()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// reportDrops logs the drops since the previous report, if any
+func (l *QLogger) reportDrops() {
+	counts := drops.sinceReport()
+	if len(counts) == 0 {
+		return
+	}
+	fields := make(map[string]interface{}, len(counts))
+	for reason, n := range counts {
+		fields[reason] = n
+	}
+	l.mustLogWith(callOptions{event: "log.dropped", fields: fields}, LevelWarn, 2, "records dropped")
+}
+
+// SetDropReportInterval 每隔 interval 输出一条按原因统计的丢弃日志数量
+func SetDropReportInterval(interval time.Duration) {
+	log.SetDropReportInterval(interval)
+}
@@ -0,0 +1,66 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDropReport(t *testing.T) {
+	drops.sinceReport()
+	before := Drops()
+
+	// A full async hook queue drops records for the hooks
+	async, _ := newBufferLogger(t)
+	release := make(chan struct{})
+	async.AddHook(func(level int, record LogRecord) { <-release })
+	async.SetAsyncHooks(1, 1, OverflowDrop)
+	t.Cleanup(func() { async.SetAsyncHooks(0, 0, OverflowDrop) })
+	for i := 0; i < 5; i++ {
+		async.Error("queued %d", i)
+	}
+	close(release)
+	async.Barrier()
+
+	// A call site over its rate limit drops its records
+	l, _ := newBufferLogger(t)
+	l.SetCallerRateLimit(1, time.Hour)
+	for i := 0; i < 3; i++ {
+		l.Error("limited %d", i)
+	}
+
+	after := Drops()
+	asyncFull := after[DropAsyncFull] - before[DropAsyncFull]
+	rateLimited := after[DropRateLimited] - before[DropRateLimited]
+	if asyncFull < 3 {
+		t.Errorf("async_full drops = %d, want at least 3", asyncFull)
+	}
+	if rateLimited != 2 {
+		t.Errorf("rate_limited drops = %d, want 2", rateLimited)
+	}
+
+	reports := make(chan LogRecord, 4)
+	l.AddHook(func(level int, record LogRecord) {
+		if record.Event == "log.dropped" {
+			reports <- record
+		}
+	})
+	l.SetDropReportInterval(10 * time.Millisecond)
+	t.Cleanup(func() { l.SetDropReportInterval(0) })
+
+	select {
+	case r := <-reports:
+		if r.Fields[DropAsyncFull] != asyncFull || r.Fields[DropRateLimited] != rateLimited {
+			t.Errorf("report fields = %v, want async_full=%d rate_limited=%d", r.Fields, asyncFull, rateLimited)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no drop report")
+	}
+
+	// Intervals without new drops are not reported
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case r := <-reports:
+		t.Errorf("report without drops: %v", r.Fields)
+	default:
+	}
+}
@@ -0,0 +1,39 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FormatDuration renders d in consistent human friendly units, e.g. 350ms,
+// 1.2s, 4m3s or 2h3m
+func FormatDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	switch {
+	case d < time.Microsecond:
+		return d.String()
+	case d < time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d < time.Second:
+		return d.Round(time.Millisecond).String()
+	case d.Round(100*time.Millisecond) < time.Minute:
+		return d.Round(100 * time.Millisecond).String()
+	}
+
+	d = d.Round(time.Second)
+	h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	var b strings.Builder
+	if h > 0 {
+		fmt.Fprintf(&b, "%dh", h)
+	}
+	if m > 0 || h > 0 && s > 0 {
+		fmt.Fprintf(&b, "%dm", m)
+	}
+	if s > 0 {
+		fmt.Fprintf(&b, "%ds", s)
+	}
+	return b.String()
+}
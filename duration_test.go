@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		0:                      "0s",
+		800 * time.Nanosecond:  "800ns",
+		1500 * time.Nanosecond: "2µs",
+		350*time.Millisecond + 400*time.Microsecond: "350ms",
+		1234 * time.Millisecond:                     "1.2s",
+		59960 * time.Millisecond:                    "1m",
+		4*time.Minute + 3*time.Second:               "4m3s",
+		2*time.Hour + 3*time.Minute:                 "2h3m",
+		2*time.Hour + 5*time.Second:                 "2h0m5s",
+		-1500 * time.Millisecond:                    "-1.5s",
+	} {
+		if got := FormatDuration(d); got != want {
+			t.Errorf("FormatDuration(%d) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDurTemplateFunc(t *testing.T) {
+	tmpl, err := template.New("custom").Funcs(TemplateFuncs()).Parse(`{{Dur .Elapsed}} {{Dur (index .Fields "wait")}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	data := struct {
+		Elapsed time.Duration
+		Fields  map[string]interface{}
+	}{1234 * time.Millisecond, map[string]interface{}{"wait": 350 * time.Millisecond}}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "1.2s 350ms" {
+		t.Errorf("template output = %q", got)
+	}
+}
@@ -0,0 +1,21 @@
+package log
+
+// Error classes for ErrorClass, grouping errors by how they are triaged
+const (
+	ErrorTransient = "transient"
+	ErrorConfig    = "config"
+	ErrorBug       = "bug"
+)
+
+// ErrorClass logs an ERROR record with an error_class field, e.g.
+// ErrorTransient, so dashboards can group errors by class
+func (l *QLogger) ErrorClass(class string, format string, v ...interface{}) {
+	opts := callOptions{fields: map[string]interface{}{"error_class": class}}
+	l.mustLogWith(opts, LevelError, 2, format, v...)
+}
+
+// ErrorClass 输出一条带有 error_class 字段的 ERROR 日志，便于按错误类别分类统计
+func ErrorClass(class string, format string, v ...interface{}) {
+	opts := callOptions{fields: map[string]interface{}{"error_class": class}}
+	log.mustLogWith(opts, LevelError, 2, format, v...)
+}
@@ -0,0 +1,20 @@
+package log
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.SetFormat(FormatJSON)
+	l.ErrorClass(ErrorTransient, "upstream %s timed out", "billing")
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["error_class"] != "transient" || obj["level"] != "ERROR" || obj["msg"] != "upstream billing timed out" {
+		t.Errorf("record = %v", obj)
+	}
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// escalation counts records of one level in a sliding window
+type escalation struct {
+	from, to int
+	count    int
+	window   time.Duration
+	seen     []time.Time
+}
+
+// SetEscalation emits a summary record at toLevel whenever count records of
+// fromLevel were logged within window. The window starts over after each
+// summary. A count of 0 disables escalation.
+func (l *QLogger) SetEscalation(fromLevel, toLevel, count int, window time.Duration) {
+	// getLevelTag panics on an invalid level
+	getLevelTag(fromLevel)
+	getLevelTag(toLevel)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if count <= 0 {
+		l.escalation = nil
+		return
+	}
+	l.escalation = &escalation{
+		from:   fromLevel,
+		to:     toLevel,
+		count:  count,
+		window: window,
+	}
+}
+
+// escalate records the level and writes the summary once the threshold is
+// crossed. It must be called with l.mu held.
+func (l *QLogger) escalate(level int, record LogRecord) {
+	e := l.escalation
+	if e == nil || level != e.from {
+		return
+	}
+
+	now := record.Time
+	e.seen = append(e.seen, now)
+	i := 0
+	for i < len(e.seen) && now.Sub(e.seen[i]) > e.window {
+		i++
+	}
+	e.seen = e.seen[i:]
+	if len(e.seen) < e.count {
+		return
+	}
+	e.seen = e.seen[:0]
+
+	summary := record
+	summary.Level = getLevelTag(e.to)
+	summary.Message = fmt.Sprintf("escalation: %d %s records within %s, last: %s",
+		e.count, strings.TrimSpace(getLevelTag(e.from)), e.window, record.Message)
+	l.emit(e.to, summary)
+}
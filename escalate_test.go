@@ -0,0 +1,37 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscalation(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l.SetClock(clock.now)
+	l.SetEscalation(LevelWarn, LevelError, 3, time.Minute)
+
+	l.Warn("slow disk")
+	l.Warn("slow disk")
+	clock.set(clock.now().Add(2 * time.Minute))
+	l.Warn("slow disk")
+	l.Warn("slow disk")
+	if strings.Contains(buf.String(), "escalation") {
+		t.Fatalf("escalated across the window: %q", buf.String())
+	}
+
+	l.Warn("slow disk")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	last := lines[len(lines)-1]
+	if !strings.Contains(last, getLevelTag(LevelError)) || !strings.Contains(last, "escalation: 3 WARN records within 1m0s, last: slow disk") {
+		t.Errorf("summary line = %q", last)
+	}
+
+	// The window starts over after a summary
+	buf.Reset()
+	l.Warn("slow disk")
+	if strings.Contains(buf.String(), "escalation") {
+		t.Errorf("escalated right after a summary: %q", buf.String())
+	}
+}
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"os"
+	"sync"
+)
+
+// exit terminates the process after a FATAL record. Tests replace it to
+// observe the exit.
+var exit = os.Exit
+
+// fatalWait blocks the Fatal calls after the first one until the process
+// exits. Tests replace it to release them.
+var fatalWait = func() { select {} }
+
+var (
+	fatalMu   sync.Mutex
+	fatalDone bool
+)
+
+// fatal logs a FATAL record, waits for the async hooks to handle it and
+// exits. Only the first call writes its record; concurrent and later calls
+// block until the process exits, so a Fatal call never returns. The exit
+// also happens when writing the record panics.
+func (l *QLogger) fatal(opts callOptions, calldepth int, format string, v ...interface{}) {
+	fatalMu.Lock()
+	if fatalDone {
+		wait := fatalWait
+		fatalMu.Unlock()
+		wait()
+		return
+	}
+	fatalDone = true
+	fatalMu.Unlock()
+
+	defer func() {
+		l.Barrier()
+		exit(-1)
+	}()
+	l.mustLogWith(opts, LevelFatal, calldepth+1, format, v...)
+}
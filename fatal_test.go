@@ -0,0 +1,143 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// interceptExit replaces the process exit for the duration of the test and
+// returns the exit codes. Fatal calls blocked after the first one are
+// released when the test ends.
+func interceptExit(t *testing.T) *[]int {
+	t.Helper()
+	var codes []int
+	exit = func(code int) { codes = append(codes, code) }
+	release := make(chan struct{})
+	fatalWait = func() { <-release }
+	t.Cleanup(func() {
+		close(release)
+		exit = os.Exit
+		fatalMu.Lock()
+		fatalWait = func() { select {} }
+		fatalDone = false
+		fatalMu.Unlock()
+	})
+	return &codes
+}
+
+func TestFatalExits(t *testing.T) {
+	codes := interceptExit(t)
+	l, buf := newBufferLogger(t)
+	l.Fatal("cannot continue: %s", "disk gone")
+
+	if len(*codes) != 1 || (*codes)[0] != -1 {
+		t.Errorf("exit codes = %v, want [-1]", *codes)
+	}
+	if got := buf.String(); !strings.Contains(got, getLevelTag(LevelFatal)) || !strings.Contains(got, "cannot continue: disk gone") {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestFatalConcurrentCalls(t *testing.T) {
+	codes := interceptExit(t)
+	l, buf := newBufferLogger(t)
+	release := make(chan struct{})
+	var blocked int32
+	fatalWait = func() {
+		atomic.AddInt32(&blocked, 1)
+		<-release
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	wg.Add(callers)
+	start := make(chan struct{})
+	for i := 0; i < callers; i++ {
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			l.Fatal("caller %d", i)
+		}(i)
+	}
+	close(start)
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&blocked) != callers-1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d Fatal calls blocked, want %d", atomic.LoadInt32(&blocked), callers-1)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := strings.Count(buf.String(), getLevelTag(LevelFatal)); n != 1 {
+		t.Errorf("%d FATAL lines, want 1:\n%s", n, buf.String())
+	}
+	if len(*codes) != 1 {
+		t.Errorf("exit codes = %v, want one exit", *codes)
+	}
+}
+
+func TestFatalDrainsAsyncHooks(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	var seen int32
+	l.AddHook(func(level int, record LogRecord) {
+		time.Sleep(20 * time.Millisecond)
+		if level == LevelFatal {
+			atomic.StoreInt32(&seen, 1)
+		}
+	})
+	l.SetAsyncHooks(1, 16, OverflowBlock)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+
+	interceptExit(t)
+	var seenAtExit int32
+	exit = func(code int) { seenAtExit = atomic.LoadInt32(&seen) }
+	l.Fatal("last words")
+	if seenAtExit != 1 {
+		t.Error("exited before the async hook handled the FATAL record")
+	}
+}
+
+func TestFatalLaterCallsBlock(t *testing.T) {
+	interceptExit(t)
+	l, buf := newBufferLogger(t)
+	l.Fatal("first")
+
+	var returned int32
+	go func() {
+		l.Fatal("second")
+		atomic.StoreInt32(&returned, 1)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if atomic.LoadInt32(&returned) != 0 {
+		t.Error("a later Fatal call returned")
+	}
+	if strings.Contains(buf.String(), "second") {
+		t.Errorf("a later Fatal call logged: %q", buf.String())
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestFatalExitsWhenWritePanics(t *testing.T) {
+	codes := interceptExit(t)
+	l, _ := newBufferLogger(t)
+	l.SetOutput(failingWriter{})
+
+	func() {
+		defer func() { recover() }()
+		l.Fatal("unwritable")
+	}()
+	if len(*codes) != 1 {
+		t.Errorf("exit codes = %v, want one exit", *codes)
+	}
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// standardFieldNames are the keys of the record fields in JSON output
+var standardFieldNames = []string{"time", "level", "id", "file", "line", "component", "event", "msg", "stack"}
+
+// fieldNames holds the map[string]string installed by SetFieldNames
+var fieldNames atomic.Value
+
+// SetFieldNames renames the record fields in JSON output to match a target
+// schema, e.g. {"msg": "message", "level": "severity"}. The keys are the
+// standard names time, level, id, file, line, component, event, msg and
+// stack. An error is returned for an unknown or empty name and when two
+// fields would end up with the same name. Replay reads records written
+// with the current names. nil restores the standard names.
+func SetFieldNames(names map[string]string) error {
+	mapped := make(map[string]string, len(names))
+	for from, to := range names {
+		if !isStandardFieldName(from) {
+			return fmt.Errorf("logger: unknown record field %q", from)
+		}
+		if to == "" {
+			return fmt.Errorf("logger: empty name for record field %q", from)
+		}
+		mapped[from] = to
+	}
+
+	used := make(map[string]string, len(standardFieldNames))
+	for _, name := range standardFieldNames {
+		to := name
+		if m, ok := mapped[name]; ok {
+			to = m
+		}
+		if other, ok := used[to]; ok {
+			return fmt.Errorf("logger: record fields %q and %q both named %q", other, name, to)
+		}
+		used[to] = name
+	}
+	fieldNames.Store(mapped)
+	return nil
+}
+
+func isStandardFieldName(name string) bool {
+	for _, n := range standardFieldNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldName returns the JSON key of the standard record field name
+func fieldName(name string) string {
+	names, _ := fieldNames.Load().(map[string]string)
+	if to, ok := names[name]; ok {
+		return to
+	}
+	return name
+}
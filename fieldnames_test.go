@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// setFieldNames renames the record fields for the duration of the test
+func setFieldNames(t *testing.T, names map[string]string) {
+	t.Helper()
+	if err := SetFieldNames(names); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetFieldNames(nil) })
+}
+
+func TestSetFieldNames(t *testing.T) {
+	setFieldNames(t, map[string]string{"msg": "message", "level": "severity", "time": "@timestamp"})
+	l, buf := newBufferLogger(t)
+	l.SetFormat(FormatJSON)
+	l.Error("renamed")
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["message"] != "renamed" || obj["severity"] != "ERROR" || obj["@timestamp"] == nil {
+		t.Errorf("record = %v", obj)
+	}
+	for _, key := range []string{"msg", "level", "time"} {
+		if _, ok := obj[key]; ok {
+			t.Errorf("standard key %q still present", key)
+		}
+	}
+
+	// Replay reads the renamed fields back
+	replayed, _ := newBufferLogger(t)
+	last := lastRecord(replayed)
+	if err := replayed.Replay(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if last.Message != "renamed" {
+		t.Errorf("replayed message = %q", last.Message)
+	}
+}
+
+func TestSetFieldNamesInvalid(t *testing.T) {
+	for _, names := range []map[string]string{
+		{"message": "msg"},
+		{"msg": ""},
+		{"msg": "level"},
+	} {
+		if err := SetFieldNames(names); err == nil {
+			t.Errorf("SetFieldNames(%v) accepted", names)
+			SetFieldNames(nil)
+		}
+	}
+}
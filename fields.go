@@ -0,0 +1,85 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Event logs an INFO record carrying a stable event name, e.g.
+// "order.created", and the given fields, separate from any human message
+func (l *QLogger) Event(name string, fields map[string]interface{}) {
+	l.mustLogWith(callOptions{event: name, fields: fields}, LevelInfo, 2, "")
+}
+
+// Event 输出一条带有事件名称和字段的 INFO 日志，便于机器统计分析
+func Event(name string, fields map[string]interface{}) {
+	log.mustLogWith(callOptions{event: name, fields: fields}, LevelInfo, 2, "")
+}
+
+// SetStaticFields sets fields carried by every record of the logger, e.g.
+// the service version. Fields passed by the call or carried by its context
+// take precedence. nil removes them.
+func (l *QLogger) SetStaticFields(fields map[string]interface{}) {
+	static := make(map[string]interface{}, len(fields))
+	for k, v := range fields {
+		static[k] = v
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.staticFields = static
+}
+
+// addStaticFields adds the static fields not already set. It must be called
+// with l.mu held.
+func (l *QLogger) addStaticFields(fields *fieldBuilder) {
+	for k, v := range l.staticFields {
+		if !fields.has(k) {
+			fields.set(k, v)
+		}
+	}
+}
+
+// SetStaticFields 设置每条日志都会附带的字段，例如服务版本，传入 nil 清除
+func SetStaticFields(fields map[string]interface{}) {
+	log.SetStaticFields(fields)
+}
+
+// formatFields renders the event and fields of a record as key=value pairs
+// for the text template, separated from the message by a space
+func formatFields(record LogRecord) string {
+	var pairs []string
+	if record.Event != "" {
+		pairs = append(pairs, "event="+formatValue(record.Event))
+	}
+	for _, key := range orderedKeys(record) {
+		pairs = append(pairs, key+"="+formatValue(record.Fields[key]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	text := strings.Join(pairs, " ")
+	if record.Message != "" {
+		text = " " + text
+	}
+	return text
+}
+
+// formatValue renders a field value, quoting it when it contains spaces
+func formatValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,27 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEvent(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, buf := newBufferLogger(t)
+	l.Event("order.created", map[string]interface{}{"order_id": 42, "sku": "a b"})
+	if got := buf.String(); !strings.Contains(got, `event=order.created order_id=42 sku="a b"`) {
+		t.Errorf("text line = %q", got)
+	}
+
+	buf.Reset()
+	l.SetFormat(FormatJSON)
+	l.Event("order.created", map[string]interface{}{"order_id": 42})
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["event"] != "order.created" || obj["order_id"] != float64(42) {
+		t.Errorf("JSON record = %v", obj)
+	}
+}
@@ -0,0 +1,302 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotateRetry is the delay before retrying a failed rotation
+const rotateRetry = time.Minute
+
+// rename renames the rotated files. Tests replace it to make rotations
+// fail.
+var rename = os.Rename
+
+// closeBeforeRename is set where an open file cannot be renamed
+const closeBeforeRename = runtime.GOOS == "windows"
+
+// Rotation selects a time based rotation schedule for a FileWriter
+type Rotation int
+
+const (
+	// RotateNever disables time based rotation
+	RotateNever Rotation = iota
+	// RotateDaily rotates at local midnight
+	RotateDaily
+	// RotateHourly rotates at the start of every hour
+	RotateHourly
+)
+
+// FileWriter writes log lines to a file and optionally rotates it on a
+// time boundary, when it grows past a maximum size, or whichever comes
+// first. Rotated files are renamed with a date suffix, e.g. app-2024-06-01.log.
+type FileWriter struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	maxSize  int64
+	rotation Rotation
+	period   time.Time
+	timer    *time.Timer
+	now      func() time.Time
+	closed   bool
+
+	// retryRotation delays the next rotation attempt after a failed one
+	retryRotation time.Time
+
+	full          diskFull
+	diskFullProbe time.Duration
+	diskFullWarn  time.Duration
+	stderr        io.Writer
+}
+
+// NewFileWriter opens or creates the file at path for appending
+func NewFileWriter(path string) (*FileWriter, error) {
+	w := &FileWriter{
+		path:          path,
+		now:           time.Now,
+		diskFullProbe: defaultDiskFullProbe,
+		diskFullWarn:  defaultDiskFullWarn,
+		stderr:        os.Stderr,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// SetRotation sets the time based rotation schedule
+func (w *FileWriter) SetRotation(r Rotation) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rotation = r
+	w.period = w.periodStart(w.now())
+	w.schedule()
+}
+
+// SetClock sets the function deciding when a rotation boundary is crossed,
+// e.g. a simulated clock in tests. nil restores time.Now.
+func (w *FileWriter) SetClock(now func() time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	w.now = now
+	w.period = w.periodStart(w.now())
+	w.schedule()
+}
+
+// SetMaxSize rotates the file once it grows past n bytes. Zero disables
+// size based rotation.
+func (w *FileWriter) SetMaxSize(n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.maxSize = n
+}
+
+// Write appends p to the file, rotating first when a boundary was crossed.
+// When the disk is full the line is dropped instead of failing, see
+// SetDiskFullRetry.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.skipWhileFull() {
+		return len(p), nil
+	}
+	if w.due(len(p)) {
+		w.rotate()
+	}
+	if w.file == nil {
+		// A previous rotation could not reopen the file
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	if err != nil {
+		if w.writeFailed(err) {
+			return len(p), nil
+		}
+		return n, err
+	}
+	w.writeSucceeded()
+	return n, nil
+}
+
+// Close stops the rotation timer and closes the file
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.closed = true
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *FileWriter) open() error {
+	f, size, err := openAppend(w.path)
+	if err != nil {
+		return err
+	}
+	w.file, w.size = f, size
+	return nil
+}
+
+// openAppend opens or creates name for appending and returns its size
+func openAppend(name string) (*os.File, int64, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
+
+// due reports whether the file must be rotated before writing n bytes
+func (w *FileWriter) due(n int) bool {
+	if !w.retryRotation.IsZero() && w.now().Before(w.retryRotation) {
+		return false
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(n) > w.maxSize {
+		return true
+	}
+	return w.rotation != RotateNever && !w.periodStart(w.now()).Equal(w.period)
+}
+
+// rotate renames the current file to its dated backup name and opens a new
+// one at path. When either step fails the failure goes to stderr, writing
+// continues in the file at path or in the current file, and the rotation is
+// retried after rotateRetry, so a failed rotation neither loses lines nor
+// floods stderr. It must be called with w.mu held.
+func (w *FileWriter) rotate() {
+	now := w.now()
+	name := w.backupName()
+	old := w.file
+	if closeBeforeRename {
+		// Windows cannot rename an open file
+		old.Close()
+	}
+	renameErr := rename(w.path, name)
+	f, size, openErr := openAppend(w.path)
+	switch {
+	case openErr == nil:
+		if !closeBeforeRename {
+			old.Close()
+		}
+		w.file, w.size = f, size
+	case closeBeforeRename:
+		// The old handle is closed: continue in the file it wrote to, or
+		// leave the reopening to the next Write
+		current := w.path
+		if renameErr == nil {
+			current = name
+		}
+		w.file, w.size, _ = openAppend(current)
+	}
+
+	err := renameErr
+	if err == nil {
+		err = openErr
+	}
+	if err != nil {
+		fmt.Fprintf(w.stderr, "%s %s %s logger: rotating %s: %v\n",
+			prefix(), stamp(now), getLevelTag(LevelWarn), w.path, err)
+		w.retryRotation = now.Add(rotateRetry)
+		return
+	}
+	w.period = w.periodStart(now)
+	w.retryRotation = time.Time{}
+}
+
+// backupName returns an unused name carrying the date of the current period
+func (w *FileWriter) backupName() string {
+	layout := "2006-01-02"
+	if w.rotation == RotateHourly {
+		layout = "2006-01-02-15"
+	}
+	period := w.period
+	if period.IsZero() {
+		period = w.now()
+	}
+
+	ext := filepath.Ext(w.path)
+	base := strings.TrimSuffix(w.path, ext) + "-" + period.Format(layout)
+	name := base + ext
+	for i := 1; fileExists(name); i++ {
+		name = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+	return name
+}
+
+// periodStart returns the start of the rotation period containing t
+func (w *FileWriter) periodStart(t time.Time) time.Time {
+	switch w.rotation {
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// schedule arms a timer that rotates the file on the next boundary even if
+// nothing is written. It must be called with w.mu held.
+func (w *FileWriter) schedule() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	var next time.Time
+	switch w.rotation {
+	case RotateDaily:
+		next = w.period.AddDate(0, 0, 1)
+	case RotateHourly:
+		next = w.period.Add(time.Hour)
+	default:
+		return
+	}
+	if next.Before(w.retryRotation) {
+		next = w.retryRotation
+	}
+	w.timer = time.AfterFunc(next.Sub(w.now()), func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.closed {
+			return
+		}
+		if w.file != nil && w.due(0) {
+			w.rotate()
+		}
+		w.schedule()
+	})
+}
+
+func fileExists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
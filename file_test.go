@@ -0,0 +1,178 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock safe for concurrent use
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = t
+}
+
+func newRotatingFile(t *testing.T, clock *fakeClock) (*FileWriter, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	w, err := NewFileWriter(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { w.Close() })
+	w.SetClock(clock.now)
+	w.SetRotation(RotateDaily)
+	return w, path
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestFileWriterDailyRotation(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 23, 59, 59, 0, time.Local)}
+	w, path := newRotatingFile(t, clock)
+
+	if _, err := w.Write([]byte("before midnight\n")); err != nil {
+		t.Fatal(err)
+	}
+	clock.set(time.Date(2024, 6, 2, 0, 0, 1, 0, time.Local))
+	if _, err := w.Write([]byte("after midnight\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := filepath.Join(filepath.Dir(path), "app-2024-06-01.log")
+	if got := readFile(t, backup); got != "before midnight\n" {
+		t.Errorf("backup = %q", got)
+	}
+	if got := readFile(t, path); got != "after midnight\n" {
+		t.Errorf("current file = %q", got)
+	}
+}
+
+func TestFileWriterRotationFailureKeepsWriting(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)}
+	w, path := newRotatingFile(t, clock)
+	var stderr bytes.Buffer
+	w.stderr = &stderr
+
+	// The file vanishing makes the rename fail
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	clock.set(time.Date(2024, 6, 2, 12, 0, 0, 0, time.Local))
+	for i := 0; i < 2; i++ {
+		if _, err := w.Write([]byte("still logging\n")); err != nil {
+			t.Fatalf("write %d after a failed rotation: %v", i, err)
+		}
+	}
+
+	if got := readFile(t, path); got != "still logging\nstill logging\n" {
+		t.Errorf("current file = %q", got)
+	}
+	if !strings.Contains(stderr.String(), "rotating") {
+		t.Errorf("rotation failure not reported: %q", stderr.String())
+	}
+	if !w.Healthy() {
+		t.Error("writer unhealthy after recovering from a failed rotation")
+	}
+}
+
+func TestFileWriterRotationFailureBacksOff(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)}
+	w, path := newRotatingFile(t, clock)
+	w.SetMaxSize(10)
+	var stderr bytes.Buffer
+	w.stderr = &stderr
+	rename = func(oldpath, newpath string) error { return os.ErrPermission }
+	t.Cleanup(func() { rename = os.Rename })
+
+	for i := 0; i < 100; i++ {
+		if _, err := w.Write([]byte("over the size\n")); err != nil {
+			t.Fatalf("write %d after a failed rotation: %v", i, err)
+		}
+	}
+	if n := strings.Count(stderr.String(), "rotating"); n != 1 {
+		t.Errorf("%d rotation warnings, want 1:\n%s", n, stderr.String())
+	}
+	if got := readFile(t, path); got != strings.Repeat("over the size\n", 100) {
+		t.Errorf("lines lost after a failed rotation: %d bytes", len(got))
+	}
+
+	// The rotation is retried once the delay elapsed
+	rename = os.Rename
+	clock.set(clock.now().Add(rotateRetry))
+	if _, err := w.Write([]byte("rotated\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "rotated\n" {
+		t.Errorf("current file after the retry = %q", got)
+	}
+	if n := strings.Count(stderr.String(), "rotating"); n != 1 {
+		t.Errorf("%d rotation warnings after the retry, want 1", n)
+	}
+}
+
+func TestFileWriterReopenFailureKeepsWriting(t *testing.T) {
+	if closeBeforeRename {
+		t.Skip("the old handle is closed for the rename")
+	}
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)}
+	w, path := newRotatingFile(t, clock)
+	var stderr bytes.Buffer
+	w.stderr = &stderr
+	if _, err := w.Write([]byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory takes the place of the renamed file, so path cannot be
+	// reopened
+	rename = func(oldpath, newpath string) error {
+		if err := os.Rename(oldpath, newpath); err != nil {
+			return err
+		}
+		return os.Mkdir(oldpath, 0755)
+	}
+	t.Cleanup(func() { rename = os.Rename })
+	clock.set(time.Date(2024, 6, 2, 12, 0, 0, 0, time.Local))
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatalf("write after a failed reopen: %v", err)
+	}
+	backup := strings.TrimSuffix(path, ".log") + "-2024-06-01.log"
+	if got := readFile(t, backup); got != "before\nafter\n" {
+		t.Errorf("old file = %q", got)
+	}
+	if !strings.Contains(stderr.String(), "rotating") {
+		t.Errorf("reopen failure not reported: %q", stderr.String())
+	}
+}
+
+func TestFileWriterClosed(t *testing.T) {
+	clock := &fakeClock{t: time.Now()}
+	w, _ := newRotatingFile(t, clock)
+	w.Close()
+	if _, err := w.Write([]byte("late\n")); err != os.ErrClosed {
+		t.Errorf("write after Close = %v, want os.ErrClosed", err)
+	}
+}
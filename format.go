@@ -0,0 +1,133 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Format selects how records are rendered
+type Format int
+
+const (
+	// FormatText renders records with the colored text template
+	FormatText Format = iota
+	// FormatJSON renders each record as a single JSON object per line
+	FormatJSON
+	// FormatAuto uses FormatText when the output is a terminal and
+	// FormatJSON otherwise
+	FormatAuto
+	// FormatTable renders records as rows of aligned columns, see
+	// SetTableColumns
+	FormatTable
+)
+
+// terminal can be implemented by outputs to override terminal detection
+type terminal interface {
+	IsTerminal() bool
+}
+
+// isTerminal reports whether w is an interactive terminal
+func isTerminal(w io.Writer) bool {
+	switch t := w.(type) {
+	case terminal:
+		return t.IsTerminal()
+	case *os.File:
+		info, err := t.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0
+	default:
+		return false
+	}
+}
+
+// SetFormat sets how records are rendered on the primary output
+func (l *QLogger) SetFormat(f Format) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.primary.format = f
+}
+
+// writeJSON writes the record as a single line JSON object. The standard
+// keys come first in a fixed order, followed by the fields sorted by key.
+func writeJSON(w io.Writer, level int, record LogRecord) error {
+	obj := newJSONObject()
+	obj.add(fieldName("time"), record.Time.Format(time.RFC3339Nano))
+	obj.add(fieldName("level"), strings.TrimSpace(getLevelTag(level)))
+	if record.ID != "" {
+		obj.add(fieldName("id"), record.ID)
+	}
+	if debugMode {
+		obj.add(fieldName("file"), record.Filename)
+		obj.add(fieldName("line"), record.LineNo)
+	}
+	if record.Component != "" {
+		obj.add(fieldName("component"), record.Component)
+	}
+	if record.Event != "" {
+		obj.add(fieldName("event"), record.Event)
+	}
+	obj.add(fieldName("msg"), record.Message)
+	if record.Stack != "" {
+		obj.add(fieldName("stack"), record.Stack)
+	}
+	for _, key := range orderedKeys(record) {
+		obj.add(key, record.Fields[key])
+	}
+
+	_, err := w.Write(obj.bytes())
+	return err
+}
+
+// jsonObject builds a JSON object preserving the order keys were added in.
+// Keys that were already added are skipped.
+type jsonObject struct {
+	buf  bytes.Buffer
+	keys map[string]bool
+}
+
+func newJSONObject() *jsonObject {
+	o := &jsonObject{keys: map[string]bool{}}
+	o.buf.WriteByte('{')
+	return o
+}
+
+func (o *jsonObject) add(key string, value interface{}) {
+	if o.keys[key] {
+		return
+	}
+	data := jsonValue(value)
+	if len(o.keys) > 0 {
+		o.buf.WriteByte(',')
+	}
+	o.keys[key] = true
+	k, _ := json.Marshal(key)
+	o.buf.Write(k)
+	o.buf.WriteByte(':')
+	o.buf.Write(data)
+}
+
+// jsonValue encodes value keeping its native JSON type: numbers, booleans,
+// maps and slices are not stringified. Errors are encoded as their message
+// and values JSON cannot represent, such as channels and functions, as a
+// marker string naming their type.
+func jsonValue(value interface{}) []byte {
+	if err, ok := value.(error); ok {
+		if _, isMarshaler := value.(json.Marshaler); !isMarshaler {
+			value = err.Error()
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprintf("!unsupported(%T)", value))
+	}
+	return data
+}
+
+func (o *jsonObject) bytes() []byte {
+	o.buf.WriteString("}\n")
+	return o.buf.Bytes()
+}
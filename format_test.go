@@ -0,0 +1,84 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ttyBuffer is a buffer that reports being a terminal
+type ttyBuffer struct {
+	bytes.Buffer
+}
+
+func (*ttyBuffer) IsTerminal() bool { return true }
+
+func TestFormatAuto(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.SetColorMode(ColorAuto)
+	l.SetFormat(FormatAuto)
+	l.Error("to a pipe")
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("non-terminal output is not JSON: %q: %v", buf.String(), err)
+	}
+	if obj["msg"] != "to a pipe" {
+		t.Errorf("msg = %v", obj["msg"])
+	}
+
+	var tty ttyBuffer
+	l.SetOutput(&tty)
+	l.Error("to a terminal")
+	got := tty.String()
+	if strings.HasPrefix(got, "{") || !strings.Contains(got, "to a terminal") {
+		t.Errorf("terminal output is not text: %q", got)
+	}
+	if !strings.Contains(got, "\x1b[") {
+		t.Errorf("terminal output is not colored: %q", got)
+	}
+}
+
+type jsonMarshaled struct{}
+
+func (jsonMarshaled) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+func TestJSONFieldTypes(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.SetFormat(FormatJSON)
+	l.Errorw("typed", OrderedFields{
+		{Key: "int", Value: 42},
+		{Key: "float", Value: 1.5},
+		{Key: "bool", Value: true},
+		{Key: "nil", Value: nil},
+		{Key: "slice", Value: []int{1, 2}},
+		{Key: "map", Value: map[string]int{"a": 1}},
+		{Key: "err", Value: errors.New("failed")},
+		{Key: "marshaler", Value: jsonMarshaled{}},
+		{Key: "chan", Value: make(chan int)},
+	})
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatalf("%q: %v", buf.String(), err)
+	}
+	want := map[string]interface{}{
+		"int":       float64(42),
+		"float":     1.5,
+		"bool":      true,
+		"nil":       nil,
+		"slice":     []interface{}{float64(1), float64(2)},
+		"map":       map[string]interface{}{"a": float64(1)},
+		"err":       "failed",
+		"marshaler": "custom",
+		"chan":      "!unsupported(chan int)",
+	}
+	for key, v := range want {
+		if got, ok := obj[key]; !ok || !reflect.DeepEqual(got, v) {
+			t.Errorf("%s = %#v, want %#v", key, got, v)
+		}
+	}
+}
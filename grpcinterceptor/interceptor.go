@@ -0,0 +1,98 @@
+// Package grpcinterceptor logs gRPC server calls. It is a separate package
+// so only programs using it depend on google.golang.org/grpc.
+//
+//	srv := grpc.NewServer(
+//		grpc.UnaryInterceptor(grpcinterceptor.UnaryServerInterceptor()),
+//		grpc.StreamInterceptor(grpcinterceptor.StreamServerInterceptor()),
+//	)
+//
+// The context passed to the handlers carries the grpc.method and
+// peer.address fields, so records logged with it, e.g. by log.InfoContext,
+// can be matched to the call.
+package grpcinterceptor
+
+import (
+	"context"
+	"time"
+
+	log "github.com/kermitbu/gant-log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor logs every unary call when it returns
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = callContext(ctx, info.FullMethod)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, "unary", start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor logs every streaming call when it returns
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := callContext(ss.Context(), info.FullMethod)
+		start := time.Now()
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, "stream", start, err)
+		return err
+	}
+}
+
+// serverStream replaces the context of a stream with the annotated one
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// callContext annotates ctx with the method and the peer of the call
+func callContext(ctx context.Context, method string) context.Context {
+	fields := map[string]interface{}{"grpc.method": method}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields["peer.address"] = p.Addr.String()
+	}
+	return log.ContextWithFields(ctx, fields)
+}
+
+// logCall logs the outcome of a call at the level matching its status code
+func logCall(ctx context.Context, kind string, start time.Time, err error) {
+	code := status.Code(err)
+	ctx = log.ContextWithFields(ctx, map[string]interface{}{
+		"grpc.kind":        kind,
+		"grpc.code":        code.String(),
+		"grpc.duration_ms": time.Since(start).Milliseconds(),
+	})
+
+	switch codeLevel(code) {
+	case log.LevelInfo:
+		log.InfoContext(ctx, "finished call")
+	case log.LevelWarn:
+		log.WarnContext(ctx, "finished call: %v", err)
+	default:
+		log.ErrorContext(ctx, "finished call: %v", err)
+	}
+}
+
+// codeLevel returns INFO for OK, WARN for codes caused by the client and
+// ERROR for server side failures
+func codeLevel(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return log.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return log.LevelWarn
+	default:
+		return log.LevelError
+	}
+}
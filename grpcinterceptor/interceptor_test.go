@@ -0,0 +1,115 @@
+package grpcinterceptor
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	log "github.com/kermitbu/gant-log"
+	"github.com/kermitbu/gant-log/logtest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	recordsMu sync.Mutex
+	records   []log.LogRecord
+	levels    []int
+)
+
+func init() {
+	log.AddHook(func(level int, record log.LogRecord) {
+		recordsMu.Lock()
+		defer recordsMu.Unlock()
+		records = append(records, record)
+		levels = append(levels, level)
+	})
+}
+
+// lastCall returns the last record logged and its level
+func lastCall(t *testing.T) (log.LogRecord, int) {
+	t.Helper()
+	recordsMu.Lock()
+	defer recordsMu.Unlock()
+	if len(records) == 0 {
+		t.Fatal("no record logged")
+	}
+	return records[len(records)-1], levels[len(levels)-1]
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	logtest.SetOutput(t)
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 4242}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	info := &grpc.UnaryServerInfo{FullMethod: "/billing.Billing/Charge"}
+	intercept := UnaryServerInterceptor()
+
+	var handlerFields bool
+	_, err := intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		log.InfoContext(ctx, "charging")
+		record, _ := lastCall(t)
+		handlerFields = record.Fields["grpc.method"] == info.FullMethod
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !handlerFields {
+		t.Error("records logged by the handler lack grpc.method")
+	}
+	record, level := lastCall(t)
+	if level != log.LevelInfo || record.Message != "finished call" {
+		t.Errorf("call logged at %d as %q", level, record.Message)
+	}
+	for key, want := range map[string]interface{}{
+		"grpc.method":  "/billing.Billing/Charge",
+		"grpc.kind":    "unary",
+		"peer.address": "10.0.0.1:4242",
+	} {
+		if got := record.Fields[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+	if _, ok := record.Fields["grpc.duration_ms"]; !ok {
+		t.Error("grpc.duration_ms missing")
+	}
+
+	for code, want := range map[codes.Code]int{
+		codes.NotFound: log.LevelWarn,
+		codes.Internal: log.LevelError,
+	} {
+		intercept(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(code, "failed")
+		})
+		if _, level := lastCall(t); level != want {
+			t.Errorf("code %v logged at %d, want %d", code, level, want)
+		}
+	}
+}
+
+// fakeStream is a server stream carrying only a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	logtest.SetOutput(t)
+	info := &grpc.StreamServerInfo{FullMethod: "/billing.Billing/Watch", IsServerStream: true}
+	err := StreamServerInterceptor()(nil, fakeStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		log.InfoContext(ss.Context(), "streaming")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, _ := lastCall(t)
+	if record.Fields["grpc.kind"] != "stream" || record.Fields["grpc.method"] != info.FullMethod {
+		t.Errorf("fields = %v", record.Fields)
+	}
+}
@@ -0,0 +1,48 @@
+package log
+
+// healthChecker is implemented by outputs that can tell whether they are
+// currently writable, such as CircuitBreaker and FileWriter
+type healthChecker interface {
+	Healthy() bool
+}
+
+// Healthy reports whether every output is currently writable: no circuit
+// breaker is open and no file is in the disk full mode. Outputs without a
+// Healthy() bool method are assumed healthy. It is meant for readiness
+// probes that should fail while logging is broken.
+func (l *QLogger) Healthy() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, s := range l.sinks {
+		if !writerHealthy(s.writer) {
+			return false
+		}
+	}
+	return true
+}
+
+func writerHealthy(w interface{}) bool {
+	h, ok := w.(healthChecker)
+	return !ok || h.Healthy()
+}
+
+// Healthy reports whether the circuit is closed and the wrapped output is
+// healthy
+func (b *CircuitBreaker) Healthy() bool {
+	b.mu.Lock()
+	open := b.open
+	b.mu.Unlock()
+	return !open && writerHealthy(b.w)
+}
+
+// Healthy reports whether the file is open and not in the disk full mode
+func (w *FileWriter) Healthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file != nil && !w.full.active
+}
+
+// Healthy 检查所有输出目标当前是否可以正常写入，可用于服务的就绪检查
+func Healthy() bool {
+	return log.Healthy()
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealthy(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	if !l.Healthy() {
+		t.Fatal("logger writing to a buffer is unhealthy")
+	}
+
+	// A circuit breaker is unhealthy while open
+	sink := &flakyWriter{down: true}
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	b := NewCircuitBreaker(sink, 1, time.Second)
+	b.now = clock.now
+	l.AddOutput(b, WithColor(ColorNever))
+	l.Error("trips the breaker")
+	if l.Healthy() || b.Healthy() {
+		t.Error("healthy with an open circuit")
+	}
+	sink.down = false
+	clock.set(clock.now().Add(time.Second))
+	l.Error("probe")
+	if !l.Healthy() {
+		t.Error("unhealthy after the circuit closed")
+	}
+
+	// A file behind a breaker is checked through it
+	w, err := NewFileWriter(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.AddOutput(NewCircuitBreaker(w, 1, time.Second))
+	if !l.Healthy() {
+		t.Error("unhealthy with a writable file")
+	}
+	w.mu.Lock()
+	w.full.active = true
+	w.mu.Unlock()
+	if l.Healthy() || w.Healthy() {
+		t.Error("healthy with a full disk")
+	}
+	w.mu.Lock()
+	w.full.active = false
+	w.mu.Unlock()
+	w.Close()
+	if l.Healthy() || w.Healthy() {
+		t.Error("healthy with a closed file")
+	}
+}
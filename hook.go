@@ -0,0 +1,199 @@
+package log
+
+import "sync"
+
+// Hook is called with every record that passes the level filter
+type Hook func(level int, record LogRecord)
+
+// OverflowPolicy decides what happens to a record when the async hook queue
+// is full
+type OverflowPolicy int
+
+const (
+	// OverflowDrop drops the record for the hooks and counts it
+	OverflowDrop OverflowPolicy = iota
+	// OverflowBlock blocks the caller until the queue has room
+	OverflowBlock
+)
+
+type hookEvent struct {
+	hooks  []Hook
+	level  int
+	record LogRecord
+
+	// barrier is set on the tokens queued by Barrier
+	barrier *sync.WaitGroup
+}
+
+// hookPool runs hooks on a bounded number of worker goroutines
+type hookPool struct {
+	queue   chan hookEvent
+	policy  OverflowPolicy
+	workers int
+	wg      sync.WaitGroup
+
+	// mu guards closed against the queue being closed while a record is
+	// sent to it
+	mu     sync.RWMutex
+	closed bool
+
+	// barrierMu serializes Barrier calls, whose tokens must not interleave
+	barrierMu sync.Mutex
+}
+
+// AddHook registers a hook called for every logged record
+func (l *QLogger) AddHook(h Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, h)
+}
+
+// SetAsyncHooks runs the hooks on the given number of workers fed by a queue
+// of queueSize records, so slow hooks do not stall the logging path. A
+// workers value of 0 restores synchronous execution.
+//
+// With more than one worker hooks may observe records out of order, and
+// even with a single worker a hook runs after the line was written and
+// possibly after later lines were written too. Records are queued after the
+// logger lock is released, so with OverflowBlock a full queue blocks only the
+// logging goroutine. A hook that logs to the same logger must not use
+// OverflowBlock: its worker would wait for room in the queue it drains.
+func (l *QLogger) SetAsyncHooks(workers, queueSize int, policy OverflowPolicy) {
+	l.mu.Lock()
+	old := l.hookPool
+	l.hookPool = nil
+	if workers > 0 {
+		l.hookPool = newHookPool(workers, queueSize, policy)
+	}
+	l.mu.Unlock()
+
+	if old != nil {
+		old.stop()
+	}
+}
+
+// DroppedHookRecords returns how many records were dropped for the hooks
+// because the async queue was full
+func (l *QLogger) DroppedHookRecords() uint64 {
+	return drops.get(DropAsyncFull)
+}
+
+func newHookPool(workers, queueSize int, policy OverflowPolicy) *hookPool {
+	p := &hookPool{
+		queue:   make(chan hookEvent, queueSize),
+		policy:  policy,
+		workers: workers,
+	}
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for ev := range p.queue {
+				if ev.barrier != nil {
+					ev.barrier.Done()
+					ev.barrier.Wait()
+					continue
+				}
+				for _, h := range ev.hooks {
+					h(ev.level, ev.record)
+				}
+			}
+		}()
+	}
+	return p
+}
+
+// stop closes the queue and waits for the queued records to be handled
+func (p *hookPool) stop() {
+	p.mu.Lock()
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+	p.wg.Wait()
+}
+
+// enqueue queues the records. It must be called without l.mu held. Records
+// that arrive after the pool was stopped run their hooks in the caller.
+func (p *hookPool) enqueue(events []hookEvent) {
+	if len(events) == 0 {
+		return
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, ev := range events {
+		switch {
+		case p.closed:
+			for _, h := range ev.hooks {
+				h(ev.level, ev.record)
+			}
+		case p.policy == OverflowBlock:
+			p.queue <- ev
+		default:
+			select {
+			case p.queue <- ev:
+			default:
+				drops.add(DropAsyncFull)
+			}
+		}
+	}
+}
+
+// Barrier blocks until the async hooks handled every record logged before
+// the call, without stopping them. It returns at once with synchronous
+// hooks.
+func (l *QLogger) Barrier() {
+	l.mu.Lock()
+	p := l.hookPool
+	if p == nil {
+		l.mu.Unlock()
+		return
+	}
+	l.mu.Unlock()
+
+	// Every worker takes one token and waits for the others, so once all
+	// tokens are taken the records queued before them were handled. Tokens
+	// of concurrent calls would leave each call with workers waiting for
+	// the other's tokens, so one call runs at a time.
+	p.barrierMu.Lock()
+	defer p.barrierMu.Unlock()
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return
+	}
+	var arrived sync.WaitGroup
+	arrived.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		p.queue <- hookEvent{barrier: &arrived}
+	}
+	p.mu.RUnlock()
+	arrived.Wait()
+}
+
+// runHooks hands the record to the hooks. Records for async hooks are held
+// until takePendingHooks. It must be called with l.mu held.
+func (l *QLogger) runHooks(level int, record LogRecord) {
+	if len(l.hooks) == 0 {
+		return
+	}
+	if l.hookPool != nil {
+		l.pendingHooks = append(l.pendingHooks, hookEvent{hooks: l.hooks, level: level, record: record})
+		return
+	}
+	for _, h := range l.hooks {
+		h(level, record)
+	}
+}
+
+// takePendingHooks returns the held records for the async hooks and their
+// pool. It must be called with l.mu held.
+func (l *QLogger) takePendingHooks() (*hookPool, []hookEvent) {
+	pending := l.pendingHooks
+	l.pendingHooks = nil
+	return l.hookPool, pending
+}
+
+// Barrier 等待异步 hook 处理完调用之前输出的全部日志
+func Barrier() {
+	log.Barrier()
+}
@@ -0,0 +1,149 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncHooksSlowHookLatency(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	var handled int32
+	l.AddHook(func(level int, record LogRecord) {
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&handled, 1)
+	})
+	l.SetAsyncHooks(1, 16, OverflowBlock)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		l.Error("record %d", i)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("10 records took %v with a 20ms async hook", elapsed)
+	}
+
+	l.Barrier()
+	if n := atomic.LoadInt32(&handled); n != 10 {
+		t.Errorf("hook handled %d records, want 10", n)
+	}
+}
+
+func TestAsyncHooksBlockDoesNotHoldLock(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	release := make(chan struct{})
+	l.AddHook(func(level int, record LogRecord) { <-release })
+	l.SetAsyncHooks(1, 1, OverflowBlock)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+
+	// The third record waits for room in the queue.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			l.Error("record %d", i)
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	configured := make(chan struct{})
+	go func() {
+		l.SetColorMode(ColorNever)
+		close(configured)
+	}()
+	select {
+	case <-configured:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logger lock held while waiting for the hook queue")
+	}
+
+	close(release)
+	<-done
+	l.Barrier()
+}
+
+func TestAsyncHooksDropWhenFull(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	release := make(chan struct{})
+	l.AddHook(func(level int, record LogRecord) { <-release })
+	l.SetAsyncHooks(1, 1, OverflowDrop)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+
+	before := l.DroppedHookRecords()
+	for i := 0; i < 5; i++ {
+		l.Error("record %d", i)
+	}
+	close(release)
+	l.Barrier()
+	// One record runs in the worker and one waits in the queue at most.
+	if dropped := l.DroppedHookRecords() - before; dropped < 3 {
+		t.Errorf("dropped %d records, want at least 3", dropped)
+	}
+}
+
+func TestAsyncHooksStopRunsLateRecords(t *testing.T) {
+	p := newHookPool(1, 1, OverflowBlock)
+	p.stop()
+
+	var got int
+	p.enqueue([]hookEvent{{hooks: []Hook{func(int, LogRecord) { got++ }}}})
+	if got != 1 {
+		t.Errorf("record after stop handled %d times, want 1", got)
+	}
+}
+
+func TestBarrier(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	var lines syncBuffer
+	l.AddHook(func(level int, record LogRecord) {
+		time.Sleep(time.Millisecond)
+		lines.Write([]byte(record.Message + "\n"))
+	})
+	l.Barrier() // synchronous hooks: returns at once
+
+	l.SetAsyncHooks(4, 256, OverflowBlock)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 50; i++ {
+			l.Error("round %d line %d", round, i)
+		}
+		l.Barrier()
+		got := lines.String()
+		for i := 0; i < 50; i++ {
+			if !strings.Contains(got, fmt.Sprintf("round %d line %d\n", round, i)) {
+				t.Fatalf("round %d line %d missing after Barrier", round, i)
+			}
+		}
+	}
+}
+
+func TestConcurrentBarriers(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	l.AddHook(func(level int, record LogRecord) { time.Sleep(50 * time.Millisecond) })
+	l.SetAsyncHooks(2, 1, OverflowBlock)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+
+	// Both workers are busy and the queue is full, so the tokens of the two
+	// calls compete for the queue
+	for i := 0; i < 3; i++ {
+		l.Error("slow %d", i)
+	}
+	done := make(chan struct{}, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			l.Barrier()
+			done <- struct{}{}
+		}()
+		time.Sleep(5 * time.Millisecond)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("concurrent Barrier calls deadlocked")
+		}
+	}
+}
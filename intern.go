@@ -0,0 +1,46 @@
+package log
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// maxInternedFormats bounds the interning table so formats built at run
+// time cannot grow it without limit
+const maxInternedFormats = 4096
+
+var (
+	internedFormats sync.Map
+	internedCount   int32
+)
+
+// SetFormatInterning makes records share one string instance per distinct
+// format string, in Format and in Message when the call has no
+// arguments. This reduces the memory held by retention features such as the
+// ring buffer when the same log sites recur.
+func (l *QLogger) SetFormatInterning(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.interning = on
+}
+
+// internFormat returns the shared instance of format, storing it on first
+// use while the table has room
+func internFormat(format string) string {
+	if s, ok := internedFormats.Load(format); ok {
+		return s.(string)
+	}
+	if atomic.LoadInt32(&internedCount) >= maxInternedFormats {
+		return format
+	}
+	s, loaded := internedFormats.LoadOrStore(format, format)
+	if !loaded {
+		atomic.AddInt32(&internedCount, 1)
+	}
+	return s.(string)
+}
+
+// SetFormatInterning 开启后相同格式串的日志共享同一个字符串，减少内存中保留的日志占用
+func SetFormatInterning(on bool) {
+	log.SetFormatInterning(on)
+}
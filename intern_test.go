@@ -0,0 +1,70 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestFormatInterning(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	l.SetFormatInterning(true)
+	var records []LogRecord
+	l.AddHook(func(level int, record LogRecord) { records = append(records, record) })
+
+	// Build equal formats in distinct memory, as a format read from
+	// configuration would be
+	for i := 0; i < 2; i++ {
+		format := strings.Repeat("x", 3) + " interned %d"
+		var none []interface{}
+		l.mustLog(LevelError, 1, format, append(none, i)...)
+	}
+
+	a, b := records[0].Format, records[1].Format
+	if a != "xxx interned %d" || unsafe.StringData(a) != unsafe.StringData(b) {
+		t.Errorf("formats %q and %q do not share storage", a, b)
+	}
+}
+
+func TestInternFormatBounded(t *testing.T) {
+	for i := 0; i < maxInternedFormats+10; i++ {
+		internFormat(fmt.Sprintf("bounded %d", i))
+	}
+	n := 0
+	internedFormats.Range(func(k, v interface{}) bool {
+		n++
+		return true
+	})
+	if n > maxInternedFormats {
+		t.Errorf("table holds %d formats, cap is %d", n, maxInternedFormats)
+	}
+}
+
+// BenchmarkFormatInterning fills the ring buffer with records whose format
+// is built at run time and reports the heap still in use afterwards
+func BenchmarkFormatInterning(b *testing.B) {
+	raw := []byte(strings.Repeat("a format read from configuration ", 4) + "%d")
+	for _, on := range []bool{false, true} {
+		b.Run(fmt.Sprintf("interning=%t", on), func(b *testing.B) {
+			l := New()
+			l.SetOutput(io.Discard)
+			l.SetColorMode(ColorNever)
+			l.SetRingBuffer(4096)
+			l.SetFormatInterning(on)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var args []interface{}
+				l.mustLog(LevelError, 1, string(raw), append(args, i)...)
+			}
+			b.StopTimer()
+			var m runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&m)
+			b.ReportMetric(float64(m.HeapInuse), "heap-B")
+			l.SetRingBuffer(0)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+//go:build linux
+// +build linux
+
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const journalSocket = "/run/systemd/journal/socket"
+
+// journalIdentifier is sent as SYSLOG_IDENTIFIER, matching the banner used
+// by the text templates
+const journalIdentifier = "IIGService"
+
+// JournaldWriter sends log records to the systemd journal using its native
+// protocol, so the caller location, level, component, event and record
+// fields arrive as structured fields instead of a plain text line. Field
+// names are upper-cased and characters the journal does not accept are
+// replaced with underscores, e.g. grpc.method becomes GRPC_METHOD. When the
+// journal socket is unavailable the records are written as text to the
+// fallback writer.
+type JournaldWriter struct {
+	mu       sync.Mutex
+	conn     *net.UnixConn
+	fallback io.Writer
+}
+
+// NewJournaldWriter connects to the local journal. If the journal cannot be
+// reached the returned writer falls back to os.Stderr.
+func NewJournaldWriter() *JournaldWriter {
+	return dialJournal(journalSocket)
+}
+
+func dialJournal(socket string) *JournaldWriter {
+	w := &JournaldWriter{fallback: os.Stderr}
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	if conn, err := net.DialUnix("unixgram", nil, addr); err == nil {
+		w.conn = conn
+	}
+	return w
+}
+
+// SetFallback sets the writer used when the journal is unavailable
+func (w *JournaldWriter) SetFallback(fallback io.Writer) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.fallback = fallback
+}
+
+// Available reports whether records are delivered to the journal
+func (w *JournaldWriter) Available() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn != nil
+}
+
+// Write sends p to the journal as an INFO message
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	fields := map[string]string{
+		"MESSAGE":  strings.TrimSuffix(string(p), "\n"),
+		"PRIORITY": strconv.Itoa(journalPriority(LevelInfo)),
+	}
+	if err := w.send(fields, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the journal
+func (w *JournaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *JournaldWriter) writeRecord(level int, record LogRecord) error {
+	fields := map[string]string{
+		"MESSAGE":           record.Message,
+		"PRIORITY":          strconv.Itoa(journalPriority(level)),
+		"SYSLOG_IDENTIFIER": journalIdentifier,
+		"CODE_FILE":         record.Filename,
+		"CODE_LINE":         strconv.Itoa(record.LineNo),
+	}
+	if record.Function != "" {
+		fields["CODE_FUNC"] = record.Function
+	}
+	if record.Component != "" {
+		fields["COMPONENT"] = record.Component
+	}
+	if record.Event != "" {
+		fields["EVENT"] = record.Event
+	}
+	if record.Stack != "" {
+		fields["STACK"] = record.Stack
+	}
+	for key, value := range record.Fields {
+		key = journalKey(key)
+		if _, ok := fields[key]; !ok {
+			fields[key] = fmt.Sprint(value)
+		}
+	}
+	line := strings.TrimSpace(getLevelTag(level)) + " " + record.Message + EndLine()
+	return w.send(fields, []byte(line))
+}
+
+// send writes the fields as one datagram, or text to the fallback writer
+// when the journal is unavailable or rejects the datagram.
+func (w *JournaldWriter) send(fields map[string]string, text []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn != nil {
+		if _, err := w.conn.Write(encodeJournalFields(fields)); err == nil {
+			return nil
+		}
+	}
+	if w.fallback == nil {
+		return nil
+	}
+	_, err := w.fallback.Write(text)
+	return err
+}
+
+// encodeJournalFields serializes fields in the journal native format. Values
+// containing a newline use the length-prefixed binary form.
+func encodeJournalFields(fields map[string]string) []byte {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		value := fields[key]
+		key = journalKey(key)
+		if !strings.Contains(value, "\n") {
+			buf.WriteString(key + "=" + value + "\n")
+			continue
+		}
+		buf.WriteString(key + "\n")
+		binary.Write(&buf, binary.LittleEndian, uint64(len(value)))
+		buf.WriteString(value + "\n")
+	}
+	return buf.Bytes()
+}
+
+// maxJournalKey is the longest field name the journal accepts
+const maxJournalKey = 64
+
+// journalKey turns name into a valid journal field name: upper case
+// letters, digits and underscores, not starting with an underscore, which
+// marks fields set by the journal itself, or a digit
+func journalKey(name string) string {
+	key := []byte(strings.ToUpper(name))
+	for i, c := range key {
+		if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			key[i] = '_'
+		}
+	}
+	k := strings.TrimLeft(string(key), "_")
+	if k == "" || k[0] >= '0' && k[0] <= '9' {
+		k = "FIELD_" + k
+	}
+	if len(k) > maxJournalKey {
+		k = k[:maxJournalKey]
+	}
+	return k
+}
+
+// journalPriority maps a log level to a syslog priority
+func journalPriority(level int) int {
+	switch level {
+	case LevelDebug:
+		return 7
+	case LevelInfo:
+		return 6
+	case LevelWarn:
+		return 4
+	case LevelError:
+		return 3
+	case LevelFatal:
+		return 2
+	default:
+		panic(errInvalidLogLevel)
+	}
+}
@@ -0,0 +1,96 @@
+//go:build linux
+// +build linux
+
+package log
+
+import (
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJournalKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"grpc.method": "GRPC_METHOD",
+		"_hidden":     "HIDDEN",
+		"user-id":     "USER_ID",
+		"1st":         "FIELD_1ST",
+		"__":          "FIELD_",
+	} {
+		if got := journalKey(name); got != want {
+			t.Errorf("journalKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestJournaldWriterStructuredFields(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "journal.sock")
+	server, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unix datagram sockets unavailable: %v", err)
+	}
+	defer server.Close()
+
+	w := dialJournal(socket)
+	defer w.Close()
+	if !w.Available() {
+		t.Fatal("writer did not connect to the socket")
+	}
+	err = w.writeRecord(LevelWarn, LogRecord{
+		Message:   "two\nlines",
+		Filename:  "main.go",
+		LineNo:    7,
+		Component: "db",
+		Event:     "query.slow",
+		Fields:    map[string]interface{}{"grpc.method": "/svc/Get", "rows": 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 4096)
+	n, err := server.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datagram := string(buf[:n])
+	for _, want := range []string{"PRIORITY=4\n", "COMPONENT=db\n", "EVENT=query.slow\n", "GRPC_METHOD=/svc/Get\n", "ROWS=3\n", "CODE_LINE=7\n", "MESSAGE\n"} {
+		if !strings.Contains(datagram, want) {
+			t.Errorf("datagram %q lacks %q", datagram, want)
+		}
+	}
+}
+
+func TestJournaldWriterFallback(t *testing.T) {
+	w := dialJournal(filepath.Join(t.TempDir(), "missing.sock"))
+	if w.Available() {
+		t.Fatal("connected to a missing socket")
+	}
+	var buf bytes.Buffer
+	w.SetFallback(&buf)
+	if err := w.writeRecord(LevelError, LogRecord{Message: "disk failed"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "ERROR disk failed\n" {
+		t.Errorf("fallback got %q", got)
+	}
+}
+
+func TestJournaldWriterSystemJournal(t *testing.T) {
+	if _, err := os.Stat(journalSocket); err != nil {
+		t.Skip("journald is not running")
+	}
+	w := NewJournaldWriter()
+	defer w.Close()
+	var buf bytes.Buffer
+	w.SetFallback(&buf)
+	if err := w.writeRecord(LevelDebug, LogRecord{Message: "journald test"}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Available() && buf.Len() != 0 {
+		t.Errorf("record went to the fallback although the journal is available: %q", buf.String())
+	}
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"bytes"
+	"runtime"
+)
+
+// WarnOnGoroutineLeak makes Close log a WARN listing the goroutine stacks
+// when more goroutines than baseline are still running, usually the value of
+// runtime.NumGoroutine() taken at startup. A negative baseline disables the
+// check.
+func (l *QLogger) WarnOnGoroutineLeak(baseline int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.leakBaseline = baseline
+}
+
+// checkGoroutineLeak logs the lingering goroutines. It must be called
+// without l.mu held.
+func (l *QLogger) checkGoroutineLeak() {
+	l.mu.Lock()
+	baseline := l.leakBaseline
+	l.mu.Unlock()
+
+	n := runtime.NumGoroutine()
+	if baseline < 0 || n <= baseline {
+		return
+	}
+	l.mustLog(LevelWarn, 3, "goroutine leak: %d goroutines running, baseline %d\n%s",
+		n, baseline, otherGoroutineStacks())
+}
+
+// otherGoroutineStacks returns the stacks of every goroutine except the
+// calling one
+func otherGoroutineStacks() []byte {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	// The calling goroutine is always dumped first
+	if i := bytes.Index(buf, []byte("\n\n")); i >= 0 {
+		return bytes.TrimSpace(buf[i+2:])
+	}
+	return nil
+}
+
+// WarnOnGoroutineLeak 在 Close 时检查协程数量是否超过 baseline，超过时输出仍在运行的协程堆栈
+func WarnOnGoroutineLeak(baseline int) {
+	log.WarnOnGoroutineLeak(baseline)
+}
@@ -0,0 +1,48 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// linger starts a goroutine running until stop is closed
+func linger(stop chan struct{}) {
+	started := make(chan struct{})
+	go func() {
+		close(started)
+		<-stop
+	}()
+	<-started
+}
+
+func TestWarnOnGoroutineLeak(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, buf := newBufferLogger(t)
+	l.WarnOnGoroutineLeak(runtime.NumGoroutine())
+
+	stop := make(chan struct{})
+	defer close(stop)
+	linger(stop)
+	l.Close()
+
+	got := buf.String()
+	if !strings.Contains(got, getLevelTag(LevelWarn)) || !strings.Contains(got, "goroutine leak:") {
+		t.Fatalf("no leak warning: %q", got)
+	}
+	if !strings.Contains(got, "log.linger.func1") {
+		t.Errorf("warning lacks the lingering goroutine stack: %q", got)
+	}
+}
+
+func TestWarnOnGoroutineLeakDisabled(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.WarnOnGoroutineLeak(-1)
+	stop := make(chan struct{})
+	defer close(stop)
+	linger(stop)
+	l.Close()
+	if strings.Contains(buf.String(), "goroutine leak") {
+		t.Errorf("warned with the check disabled: %q", buf.String())
+	}
+}
@@ -0,0 +1,161 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	componentMu     sync.RWMutex
+	componentLevels = map[string]int{}
+)
+
+var (
+	quietMu     sync.Mutex
+	quiet       bool
+	levelBefore int
+)
+
+// SetLevel sets the global minimum level
+func SetLevel(level int) {
+	getLevelTag(level)
+	atomic.StoreInt32(&logLevel, int32(level))
+}
+
+// GetLevel returns the global minimum level
+func GetLevel() int {
+	return int(atomic.LoadInt32(&logLevel))
+}
+
+// SetQuiet suppresses everything below ERROR while on. Turning it off
+// restores the level that was in effect when it was turned on.
+func SetQuiet(on bool) {
+	quietMu.Lock()
+	defer quietMu.Unlock()
+	if on == quiet {
+		return
+	}
+	quiet = on
+	if on {
+		levelBefore = GetLevel()
+		SetLevel(LevelError)
+	} else {
+		SetLevel(levelBefore)
+	}
+}
+
+// SetComponentLevel overrides the minimum level for records logged through
+// Component(name)
+func SetComponentLevel(name string, level int) {
+	getLevelTag(level)
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	componentLevels[name] = level
+}
+
+// ParseLevel returns the level named by s, e.g. "info" or "WARN"
+func ParseLevel(s string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	case "fatal":
+		return LevelFatal, nil
+	default:
+		return 0, fmt.Errorf("%v: %q", errInvalidLogLevel, s)
+	}
+}
+
+// SetLevelsFromString applies a whole level policy such as
+// "default=info,db=debug,auth=warn". The "default" entry, or an entry
+// without a name, sets the global level and every other entry sets the
+// level of that component. Nothing is applied if any entry is invalid.
+func SetLevelsFromString(spec string) error {
+	global := -1
+	components := map[string]int{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value := "default", entry
+		if i := strings.Index(entry, "="); i >= 0 {
+			name, value = strings.TrimSpace(entry[:i]), entry[i+1:]
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return err
+		}
+		if name == "default" || name == "" {
+			global = level
+		} else {
+			components[name] = level
+		}
+	}
+
+	if global >= 0 {
+		SetLevel(global)
+	}
+	componentMu.Lock()
+	defer componentMu.Unlock()
+	for name, level := range components {
+		componentLevels[name] = level
+	}
+	return nil
+}
+
+// levelEnabled reports whether a record of level is logged for component
+func levelEnabled(component string, level int) bool {
+	if component != "" {
+		componentMu.RLock()
+		min, ok := componentLevels[component]
+		componentMu.RUnlock()
+		if ok {
+			return level >= min
+		}
+	}
+	return level >= GetLevel()
+}
+
+// ComponentLogger logs records tagged with a component name, filtered by
+// the component level when one was set
+type ComponentLogger struct {
+	name string
+}
+
+// Component returns a logger for the named component
+func Component(name string) *ComponentLogger {
+	return &ComponentLogger{name: name}
+}
+
+// Debug logs a DEBUG record for the component
+func (c *ComponentLogger) Debug(format string, v ...interface{}) {
+	log.mustLogWith(callOptions{component: c.name}, LevelDebug, 2, format, v...)
+}
+
+// Info logs an INFO record for the component
+func (c *ComponentLogger) Info(format string, v ...interface{}) {
+	log.mustLogWith(callOptions{component: c.name}, LevelInfo, 2, format, v...)
+}
+
+// Warn logs a WARN record for the component
+func (c *ComponentLogger) Warn(format string, v ...interface{}) {
+	log.mustLogWith(callOptions{component: c.name}, LevelWarn, 2, format, v...)
+}
+
+// Error logs an ERROR record for the component
+func (c *ComponentLogger) Error(format string, v ...interface{}) {
+	log.mustLogWith(callOptions{component: c.name}, LevelError, 2, format, v...)
+}
+
+// Fatal logs a FATAL record for the component and exits
+func (c *ComponentLogger) Fatal(format string, v ...interface{}) {
+	log.fatal(callOptions{component: c.name}, 2, format, v...)
+}
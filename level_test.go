@@ -0,0 +1,67 @@
+package log
+
+import "testing"
+
+// resetComponentLevels drops the component levels set by the test
+func resetComponentLevels(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		componentMu.Lock()
+		defer componentMu.Unlock()
+		componentLevels = map[string]int{}
+	})
+}
+
+func TestSetLevelsFromString(t *testing.T) {
+	setLevel(t, LevelError)
+	resetComponentLevels(t)
+
+	if err := SetLevelsFromString("default=info, db=debug,auth=WARN"); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetLevel(); got != LevelInfo {
+		t.Errorf("global level = %d, want %d", got, LevelInfo)
+	}
+	for _, c := range []struct {
+		component string
+		level     int
+		want      bool
+	}{
+		{"db", LevelDebug, true},
+		{"auth", LevelInfo, false},
+		{"auth", LevelWarn, true},
+		{"other", LevelDebug, false},
+		{"other", LevelInfo, true},
+	} {
+		if got := levelEnabled(c.component, c.level); got != c.want {
+			t.Errorf("levelEnabled(%q, %d) = %t, want %t", c.component, c.level, got, c.want)
+		}
+	}
+}
+
+func TestSetLevelsFromStringInvalid(t *testing.T) {
+	setLevel(t, LevelError)
+	resetComponentLevels(t)
+
+	if err := SetLevelsFromString("default=debug,db=verbose"); err == nil {
+		t.Fatal("no error for an unknown level name")
+	}
+	if GetLevel() != LevelError || levelEnabled("db", LevelDebug) {
+		t.Error("levels applied from an invalid spec")
+	}
+}
+
+func TestSetQuiet(t *testing.T) {
+	setLevel(t, LevelDebug)
+	t.Cleanup(func() { SetQuiet(false) })
+
+	SetQuiet(true)
+	if got := GetLevel(); got != LevelError {
+		t.Errorf("quiet level = %d, want %d", got, LevelError)
+	}
+	SetQuiet(true)
+	SetQuiet(false)
+	if got := GetLevel(); got != LevelDebug {
+		t.Errorf("level after quiet = %d, want %d", got, LevelDebug)
+	}
+}
@@ -1,13 +1,16 @@
 package log
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 
@@ -16,12 +19,13 @@ import (
 
 var errInvalidLogLevel = errors.New("logger: invalid log level")
 
+// Log levels, from the most verbose to the most severe
 const (
-	levelDebug = iota
-	levelInfo
-	levelWarn
-	levelError
-	levelFatal
+	LevelDebug = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
 )
 
 var (
@@ -32,22 +36,84 @@ var (
 
 var debugMode = os.Getenv("IIGSDEBUG") == "1"
 
-var logLevel = levelDebug
+// logLevel is the global minimum level. Debug records are only logged by
+// default when IIGSDEBUG=1.
+var logLevel = defaultLevel()
+
+func defaultLevel() int32 {
+	if debugMode {
+		return LevelDebug
+	}
+	return LevelInfo
+}
 
 // QLogger logs logging records to the specified io.Writer
 type QLogger struct {
-	mu     sync.Mutex
-	output io.Writer
+	mu      sync.Mutex
+	primary *sink
+	sinks   []*sink
+	owned   []io.Closer
+
+	strictOutputNames bool
+
+	lineSeparator      string
+	messageEncoder     func(string) string
+	continuationIndent string
+	alertLevel         int
+	leakBaseline       int
+	pooling            bool
+	interning          bool
+	dropReport         chan struct{}
+	autoStackLevel     int
+
+	started      time.Time
+	levelCounts  [LevelFatal + 1]uint64
+	closeSummary bool
+	shuttingDown bool
+
+	hooks    []Hook
+	hookPool *hookPool
+	ring     *recordRing
+
+	escalation  *escalation
+	adaptive    *adaptive
+	callerLimit *callerLimit
+
+	callerFormat CallerFormat
+	strictFormat bool
+
+	emitConfig    bool
+	configEmitted bool
+
+	clock func() time.Time
+
+	// pendingHooks holds the records for the async hooks until l.mu is
+	// released
+	pendingHooks []hookEvent
+
+	staticFields map[string]interface{}
 }
 
 // LogRecord represents a log record and contains the timestamp when the record
 // was created, an increasing id, level and the actual formatted log line.
+// Format is the format string passed to the log call.
 type LogRecord struct {
-	ID       string
-	Level    string
-	Message  string
-	Filename string
-	LineNo   int
+	Time      time.Time
+	ID        string
+	Level     string
+	Message   string
+	Format    string
+	Filename  string
+	LineNo    int
+	Function  string
+	Caller    string
+	Component string
+	Event     string
+	Fields    map[string]interface{}
+	Stack     string
+
+	// order lists the keys of Fields that keep their order in the output
+	order []string
 }
 
 var (
@@ -55,21 +121,35 @@ var (
 	debugLogRecordTemplate *template.Template
 )
 
-// getQLogger initializes the logger instance with a NewColorWriter output
-// and returns a singleton
-func getQLogger(w io.Writer) *QLogger {
+// TemplateFuncs returns the functions available to the record templates,
+// for custom templates rendering records the same way: Prefix, Stamp, Sep,
+// Fields, EndLine, Now and Dur, which formats a time.Duration with
+// FormatDuration, e.g. {{Dur .Elapsed}}
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"Now":     Now,
+		"EndLine": EndLine,
+		"Fields":  formatFields,
+		"Sep":     separator,
+		"Dur":     FormatDuration,
+		"Prefix":  prefix,
+		"Stamp":   stamp,
+	}
+}
+
+// getQLogger initializes the logger instance and returns a singleton. The
+// primary output is bound to os.Stdout on the first write unless SetOutput
+// was called before, so it can be configured at any time before logging.
+func getQLogger() *QLogger {
 	once.Do(func() {
 		var (
 			err             error
-			debugLogFormat  = `[IIGService] {{Now "2006/01/02 15:04:05"}} {{.Level}} ▶ {{.ID}} {{.Filename}}:{{.LineNo}} {{.Message}}{{EndLine}}`
-			relaseLogFormat = `[IIGService] {{Now "2006/01/02 15:04:05"}} {{.Level}} ▶ {{.ID}} {{.Message}}{{EndLine}}`
+			debugLogFormat  = `{{Prefix}} {{Stamp .Time}} {{.Level}} {{Sep}} {{.ID}} {{.Caller}} {{if .Component}}[{{.Component}}] {{end}}{{.Message}}{{Fields .}}{{if .Stack}}{{EndLine}}{{.Stack}}{{end}}{{EndLine}}`
+			relaseLogFormat = `{{Prefix}} {{Stamp .Time}} {{.Level}} {{Sep}} {{.ID}} {{if .Component}}[{{.Component}}] {{end}}{{.Message}}{{Fields .}}{{if .Stack}}{{EndLine}}{{.Stack}}{{end}}{{EndLine}}`
 		)
 
 		// Initialize and parse logging templates
-		funcs := template.FuncMap{
-			"Now":     Now,
-			"EndLine": EndLine,
-		}
+		funcs := TemplateFuncs()
 
 		if debugMode {
 			logRecordTemplate, err = template.New("debugLogFormat").Funcs(funcs).Parse(debugLogFormat)
@@ -83,16 +163,46 @@ func getQLogger(w io.Writer) *QLogger {
 			}
 		}
 
-		instance = &QLogger{output: colors.NewColorWriter(w)}
+		instance = newQLogger()
 	})
 	return instance
 }
 
+func newQLogger() *QLogger {
+	l := &QLogger{
+		primary:        newSink(nil),
+		lineSeparator:  `\n`,
+		alertLevel:     alertBoxOff,
+		leakBaseline:   -1,
+		autoStackLevel: LevelFatal + 1,
+		started:        time.Now(),
+	}
+	l.sinks = []*sink{l.primary}
+	return l
+}
+
+// New returns a logger independent of the package level one, with its own
+// outputs, hooks, clock and settings, writing to os.Stdout until SetOutput
+// is called. The level, the component levels, the drop counters, the banner
+// prefix, the time format and the separator glyph are shared by all loggers.
+func New() *QLogger {
+	getQLogger()
+	return newQLogger()
+}
+
 // SetOutput sets the logger output destination
 func (l *QLogger) SetOutput(w io.Writer) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	l.output = colors.NewColorWriter(w)
+	l.primary.setWriter(w)
+}
+
+// Output returns the logger output destination
+func (l *QLogger) Output() io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.primary.bind()
+	return l.primary.writer
 }
 
 // Now returns the current local time in the specified layout
@@ -100,103 +210,448 @@ func Now(layout string) string {
 	return time.Now().Format(layout)
 }
 
+// separatorGlyph holds the glyph separating the banner from the rest of
+// the line. It is shared by all loggers and read on every text line, so it
+// is an atomic value rather than guarded by a logger's mutex.
+var separatorGlyph atomic.Value
+
+const defaultGlyph = "▶"
+
+func separator() string {
+	if glyph, ok := separatorGlyph.Load().(string); ok {
+		return glyph
+	}
+	return defaultGlyph
+}
+
+// SetSeparatorGlyph sets the glyph separating the banner from the rest of
+// the line, "▶" by default. The glyph is shared by all loggers.
+func (l *QLogger) SetSeparatorGlyph(glyph string) {
+	separatorGlyph.Store(glyph)
+}
+
+const (
+	defaultPrefix     = "[IIGService]"
+	defaultTimeFormat = "2006/01/02 15:04:05"
+)
+
+// bannerPrefix and timeFormat hold the banner and the timestamp layout of
+// text lines. Like the separator glyph they are shared by all loggers.
+var (
+	bannerPrefix atomic.Value
+	timeFormat   atomic.Value
+)
+
+func prefix() string {
+	if p, ok := bannerPrefix.Load().(string); ok {
+		return p
+	}
+	return defaultPrefix
+}
+
+func timeLayout() string {
+	if layout, ok := timeFormat.Load().(string); ok {
+		return layout
+	}
+	return defaultTimeFormat
+}
+
+func stamp(t time.Time) string {
+	return t.Format(timeLayout())
+}
+
+// SetPrefix sets the banner starting every text line, "[IIGService]" by
+// default. The prefix is shared by all loggers.
+func (l *QLogger) SetPrefix(p string) {
+	bannerPrefix.Store(p)
+}
+
+// SetTimeFormat sets the layout of the timestamp in text lines,
+// "2006/01/02 15:04:05" by default. The layout is shared by all loggers.
+func (l *QLogger) SetTimeFormat(layout string) {
+	timeFormat.Store(layout)
+}
+
 // EndLine returns the a newline escape character
 func EndLine() string {
 	return "\n"
 }
 
-func (l *QLogger) getLevelTag(level int) string {
+func getLevelTag(level int) string {
 	switch level {
-	case levelDebug:
+	case LevelDebug:
 		return "DEBUG"
-	case levelInfo:
+	case LevelInfo:
 		return "INFO "
-	case levelWarn:
+	case LevelWarn:
 		return "WARN "
-	case levelError:
+	case LevelError:
 		return "ERROR"
-	case levelFatal:
+	case LevelFatal:
 		return "FATAL"
 	default:
 		panic(errInvalidLogLevel)
 	}
 }
 
-func (l *QLogger) getColorLevel(level int) string {
+// levelColor returns the color function used to render the given level
+func levelColor(level int) func(string) string {
+	if currentTheme() == ThemeIntensity {
+		return intensityColor(level)
+	}
 	switch level {
-	case levelDebug:
-		return colors.CyanBold(l.getLevelTag(level))
-	case levelInfo:
-		return colors.GreenBold(l.getLevelTag(level))
-	case levelWarn:
-		return colors.YellowBold(l.getLevelTag(level))
-	case levelError:
-		return colors.RedBold(l.getLevelTag(level))
-	case levelFatal:
-		return colors.MagentaBold(l.getLevelTag(level))
+	case LevelDebug:
+		return colors.CyanBold
+	case LevelInfo:
+		return colors.GreenBold
+	case LevelWarn:
+		return colors.YellowBold
+	case LevelError:
+		return colors.RedBold
+	case LevelFatal:
+		return colors.MagentaBold
 	default:
 		panic(errInvalidLogLevel)
 	}
 }
 
+var levelColorNames = [...]string{
+	LevelDebug: "cyan",
+	LevelInfo:  "green",
+	LevelWarn:  "yellow",
+	LevelError: "red",
+	LevelFatal: "magenta",
+}
+
+// printColorLegend writes a single line with every level tag rendered in
+// its own color, e.g. "DEBUG=cyan INFO=green ...", to the primary output.
+// The tags are left plain on an uncolored output and nothing is written to
+// an output that is not rendered as text.
+func (l *QLogger) printColorLegend() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := l.primary
+	s.bind()
+	if s.records != nil || s.effectiveFormat() != FormatText {
+		return
+	}
+	names := levelColorNames
+	if currentTheme() == ThemeIntensity {
+		names = intensityColorNames
+	}
+	legend := make([]string, 0, len(names))
+	for level, name := range names {
+		tag := strings.TrimSpace(getLevelTag(level))
+		if s.colored() {
+			tag = levelColor(level)(tag)
+		}
+		legend = append(legend, tag+"="+name)
+	}
+	io.WriteString(s.output, strings.Join(legend, " ")+EndLine())
+}
+
+// callOptions carries per call data that is not part of the format
+// arguments
+type callOptions struct {
+	ctx       context.Context
+	component string
+	event     string
+	fields    map[string]interface{}
+	order     []string
+	frame     *runtime.Frame
+	noCaller  bool
+	time      time.Time
+	stack     string
+}
+
+// enabled reports whether a record of level is logged with these options
+func (opts callOptions) enabled(level int) bool {
+	if level == LevelDebug {
+		if sampled, ok := traceSampled(opts.ctx); ok {
+			return sampled
+		}
+	}
+	return levelEnabled(opts.component, level)
+}
+
 // mustLog logs the message according to the specified level and arguments.
 // It panics in case of an error.
 func (l *QLogger) mustLog(level int, calldepth int, message string, args ...interface{}) {
-	if level < logLevel {
+	l.mustLogWith(callOptions{}, level, calldepth+1, message, args...)
+}
+
+// mustLogWith is mustLog with additional per call options
+func (l *QLogger) mustLogWith(opts callOptions, level int, calldepth int, message string, args ...interface{}) {
+	if !opts.enabled(level) {
 		return
 	}
-	// Acquire the lock
+	// Acquire the lock. Records for the async hooks are queued once it is
+	// released, so a full queue never blocks while the lock is held.
+	var (
+		pool    *hookPool
+		pending []hookEvent
+	)
+	defer func() { pool.enqueue(pending) }()
 	l.mu.Lock()
-	defer l.mu.Unlock()
+	defer func() {
+		pool, pending = l.takePendingHooks()
+		l.mu.Unlock()
+	}()
 
-	var ok bool
-	_, file, line, ok := runtime.Caller(calldepth)
-	if !ok {
-		file = "???"
-		line = 0
+	var (
+		function, file string
+		line           int
+		pc             uintptr
+	)
+	switch {
+	case opts.noCaller:
+	case opts.frame != nil:
+		function = shortFuncName(opts.frame.Function)
+		file, line = opts.frame.File, opts.frame.Line
+	default:
+		var ok bool
+		pc, file, line, ok = runtime.Caller(calldepth)
+		if ok {
+			function = funcName(pc)
+		} else {
+			file = "???"
+			line = 0
+		}
+	}
+
+	now := opts.time
+	if now.IsZero() {
+		now = l.now()
 	}
+	if l.callerLimit != nil && pc != 0 && !l.allowCaller(pc, now, function, file, line) {
+		return
+	}
+	l.emitConfigLine(now)
 
-	record := LogRecord{
-		Level:    l.getColorLevel(level),
-		Message:  fmt.Sprintf(message, args...),
-		Filename: filepath.Base(file),
-		LineNo:   line,
+	fields := fieldBuilder{base: opts.fields, pooled: l.pooling}
+	addContextFields(&fields, opts.ctx)
+	l.addStaticFields(&fields)
+	if l.shuttingDown {
+		fields.set("shutdown", true)
 	}
 
-	err := logRecordTemplate.Execute(l.output, record)
+	record := l.newRecord()
+	*record = LogRecord{
+		Time:      now,
+		Level:     getLevelTag(level),
+		Message:   fmt.Sprintf(message, args...),
+		Format:    message,
+		LineNo:    line,
+		Function:  function,
+		Component: opts.component,
+		Event:     opts.event,
+		Fields:    fields.fields(),
+		order:     opts.order,
+	}
+	if l.interning {
+		record.Format = internFormat(message)
+		if len(args) == 0 && record.Message == message {
+			record.Message = record.Format
+		}
+	}
+	if file != "" {
+		record.Filename = filepath.Base(file)
+		record.Caller = l.formatCaller(function, record.Filename, line)
+	}
+	switch {
+	case opts.stack != "":
+		record.Stack = opts.stack
+	case level >= l.autoStackLevel:
+		record.Stack = callerStack(calldepth)
+	}
+
+	l.emit(level, *record)
+	if l.strictFormat && len(args) == 0 && hasFormatVerb(message) {
+		l.warnMissingArgs(*record, message)
+	}
+	l.escalate(level, *record)
+	l.adapt(level, record.Time)
+	l.releaseRecord(record, &fields)
+}
+
+// emit writes the record to the outputs and hands it to the hooks. It must
+// be called with l.mu held.
+func (l *QLogger) emit(level int, record LogRecord) {
+	l.levelCounts[level]++
+	err := l.write(level, record)
 	if err != nil {
 		panic(err)
 	}
+	l.retain(record)
+	l.runHooks(level, record)
+}
+
+// Debug logs a DEBUG record
+func (l *QLogger) Debug(format string, v ...interface{}) {
+	l.mustLog(LevelDebug, 2, format, v...)
 }
 
-var log = getQLogger(os.Stdout)
+// Info logs an INFO record
+func (l *QLogger) Info(format string, v ...interface{}) {
+	l.mustLog(LevelInfo, 2, format, v...)
+}
+
+// Warn logs a WARN record
+func (l *QLogger) Warn(format string, v ...interface{}) {
+	l.mustLog(LevelWarn, 2, format, v...)
+}
+
+// Error logs an ERROR record
+func (l *QLogger) Error(format string, v ...interface{}) {
+	l.mustLog(LevelError, 2, format, v...)
+}
+
+// Fatal logs a FATAL record and exits
+func (l *QLogger) Fatal(format string, v ...interface{}) {
+	l.fatal(callOptions{}, 2, format, v...)
+}
+
+var log = getQLogger()
 
 // Debug 级别最低的，一般不用，在使用前最好加上if判断
 func Debug(format string, v ...interface{}) {
-	if debugMode {
-		log.mustLog(levelDebug, 2, format, v...)
-	}
+	log.mustLog(LevelDebug, 2, format, v...)
 }
 
 // Info 反馈给用户用的信息，可以作为产品的一部分
 func Info(format string, v ...interface{}) {
-	log.mustLog(levelInfo, 2, format, v...)
+	log.mustLog(LevelInfo, 2, format, v...)
 }
 
 // Warn 检测到了一个不正常状态，做一些修复性的工作可以系统恢复到正常状态来
 func Warn(format string, v ...interface{}) {
-	log.mustLog(levelWarn, 2, format, v...)
+	log.mustLog(LevelWarn, 2, format, v...)
 }
 
 // Error 检测到了一个不正常状态，做一些修复性的工作不确定系统是否能恢复到正常状态来
 func Error(format string, v ...interface{}) {
-	log.mustLog(levelError, 2, format, v...)
+	log.mustLog(LevelError, 2, format, v...)
 }
 
 // Fatal 检测到了一个不正常状态，相当严重，并且肯定这个错误无法修复，如果系统运行下去会越来越乱
 func Fatal(format string, v ...interface{}) {
-	log.mustLog(levelFatal, 2, format, v...)
-	os.Exit(-1)
+	log.fatal(callOptions{}, 2, format, v...)
+}
+
+// SetOutput 设置日志的输出目标，例如 NewFileWriter 创建的文件
+func SetOutput(w io.Writer) {
+	log.SetOutput(w)
+}
+
+// Output 返回当前的日志输出目标
+func Output() io.Writer {
+	return log.Output()
+}
+
+// SetCallerFormat 设置调用位置字段的格式，例如 handler.go:42 或 handle@handler.go:42
+func SetCallerFormat(f CallerFormat) {
+	log.SetCallerFormat(f)
+}
+
+// AddOutput 增加一个输出目标，每条日志会同时写到所有输出
+func AddOutput(w io.Writer, opts ...OutputOption) {
+	log.AddOutput(w, opts...)
+}
+
+// AddNamedOutput 增加一个具名的输出目标，名称重复时替换原有的输出
+func AddNamedOutput(name string, w io.Writer, opts ...OutputOption) error {
+	return log.AddNamedOutput(name, w, opts...)
+}
+
+// SetStrictOutputNames 开启后 AddNamedOutput 遇到重复名称时返回错误而不是替换
+func SetStrictOutputNames(strict bool) {
+	log.SetStrictOutputNames(strict)
+}
+
+// SetFileWithJSONSidecar 将文本日志写到 path，同时将 JSON 格式写到 path.json
+func SetFileWithJSONSidecar(path string) error {
+	return log.SetFileWithJSONSidecar(path)
+}
+
+// SetFileWithConsoleMirror 将全部日志写到 path，同时将不低于 consoleMinLevel 的日志输出到标准输出
+func SetFileWithConsoleMirror(path string, consoleMinLevel int) error {
+	return log.SetFileWithConsoleMirror(path, consoleMinLevel)
+}
+
+// SetColorMode 设置主输出是否输出彩色文本
+func SetColorMode(mode ColorMode) {
+	log.SetColorMode(mode)
+}
+
+// SetSingleLine 开启后将多行日志合并为一行输出，便于日志收集系统处理
+func SetSingleLine(on bool) {
+	log.SetSingleLine(on)
+}
+
+// SetLineSeparator 设置合并多行日志时用来替换换行符的字符串
+func SetLineSeparator(sep string) {
+	log.SetLineSeparator(sep)
+}
+
+// SetSeparatorGlyph 设置日志前缀与正文之间的分隔符，默认为 ▶，对所有 logger 生效
+func SetSeparatorGlyph(glyph string) {
+	log.SetSeparatorGlyph(glyph)
+}
+
+// SetContinuationIndent 设置多行日志中第二行及之后各行的缩进
+func SetContinuationIndent(indent string) {
+	log.SetContinuationIndent(indent)
+}
+
+// SetMessageEncoder 设置写出前对日志正文进行编码的函数，例如 base64
+func SetMessageEncoder(encode func(string) string) {
+	log.SetMessageEncoder(encode)
+}
+
+// SetPrefix 设置每行文本日志开头的前缀，默认为 [IIGService]，对所有 logger 生效
+func SetPrefix(p string) {
+	log.SetPrefix(p)
+}
+
+// SetTimeFormat 设置文本日志中时间的格式，对所有 logger 生效
+func SetTimeFormat(layout string) {
+	log.SetTimeFormat(layout)
+}
+
+// Close 关闭日志打开的文件等资源，并等待异步钩子执行完毕
+func Close() error {
+	return log.Close()
+}
+
+// SetStrictFormat 开启后，格式串中含有格式化动词却没有传入参数时会额外输出一条警告
+func SetStrictFormat(strict bool) {
+	log.SetStrictFormat(strict)
+}
+
+// AddHook 注册一个钩子，每条输出的日志都会调用它
+func AddHook(h Hook) {
+	log.AddHook(h)
+}
+
+// SetAsyncHooks 使用有限数量的协程异步执行钩子，workers 为 0 时恢复同步执行
+func SetAsyncHooks(workers, queueSize int, policy OverflowPolicy) {
+	log.SetAsyncHooks(workers, queueSize, policy)
+}
+
+// SetFormat 设置日志的输出格式，FormatAuto 会在终端上输出文本、其他情况输出 JSON
+func SetFormat(f Format) {
+	log.SetFormat(f)
+}
+
+// SetEscalation 在 window 时间内出现 count 条 fromLevel 级别的日志时，额外输出一条 toLevel 级别的汇总日志
+func SetEscalation(fromLevel, toLevel, count int, window time.Duration) {
+	log.SetEscalation(fromLevel, toLevel, count, window)
+}
+
+// PrintColorLegend 输出一行各日志级别所对应的颜色说明，方便不熟悉配色的用户
+func PrintColorLegend() {
+	log.printColorLegend()
 }
 
 func Trace(url string, code int, result string) {
@@ -0,0 +1,127 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newBufferLogger returns an independent logger writing uncolored text to
+// the returned buffer
+func newBufferLogger(t *testing.T) (*QLogger, *bytes.Buffer) {
+	t.Helper()
+	l := New()
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+	l.SetColorMode(ColorNever)
+	return l, &buf
+}
+
+// setLevel sets the global level for the duration of the test
+func setLevel(t *testing.T, level int) {
+	t.Helper()
+	old := GetLevel()
+	SetLevel(level)
+	t.Cleanup(func() { SetLevel(old) })
+}
+
+func TestPrintColorLegend(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.SetColorMode(ColorAlways)
+	l.printColorLegend()
+
+	line := buf.String()
+	if strings.Count(line, "\n") != 1 {
+		t.Fatalf("legend is not a single line: %q", line)
+	}
+	for level, name := range levelColorNames {
+		tag := strings.TrimSpace(getLevelTag(level))
+		want := levelColor(level)(tag) + "=" + name
+		if !strings.Contains(line, want) {
+			t.Errorf("legend %q lacks %q", line, want)
+		}
+	}
+}
+
+func TestPrintColorLegendUncolored(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.printColorLegend()
+	if got := buf.String(); strings.Contains(got, "\x1b[") || !strings.Contains(got, "ERROR=red") {
+		t.Errorf("uncolored legend = %q", got)
+	}
+
+	buf.Reset()
+	l.SetFormat(FormatJSON)
+	l.printColorLegend()
+	if buf.Len() != 0 {
+		t.Errorf("legend written to a JSON output: %q", buf.String())
+	}
+}
+
+func TestSeparatorGlyph(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	SetSeparatorGlyph("|")
+	t.Cleanup(func() { SetSeparatorGlyph(defaultGlyph) })
+
+	l.Error("glyph")
+	if got := buf.String(); !strings.Contains(got, getLevelTag(LevelError)+" | ") || strings.Contains(got, "▶") {
+		t.Errorf("line = %q", got)
+	}
+}
+
+func TestDefaultOutputBoundLazily(t *testing.T) {
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	prev := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = prev })
+
+	configured := New()
+	if configured.primary.writer != nil {
+		t.Fatalf("output bound before the first record: %T", configured.primary.writer)
+	}
+	var buf bytes.Buffer
+	configured.SetOutput(&buf)
+	configured.Error("first")
+	if !strings.Contains(buf.String(), "first") {
+		t.Errorf("configured output = %q", buf.String())
+	}
+
+	unconfigured := New()
+	unconfigured.SetColorMode(ColorNever)
+	unconfigured.Error("second")
+	got := readFile(t, stdout.Name())
+	if strings.Contains(got, "first") || !strings.Contains(got, "second") {
+		t.Errorf("stdout = %q", got)
+	}
+}
+
+func TestSeparatorGlyphSharedAcrossLoggers(t *testing.T) {
+	t.Cleanup(func() { SetSeparatorGlyph(defaultGlyph) })
+	l, buf := newBufferLogger(t)
+	other := New()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			other.SetSeparatorGlyph("|")
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		l.Error("concurrent")
+	}
+	wg.Wait()
+
+	buf.Reset()
+	l.Error("after")
+	if got := buf.String(); !strings.Contains(got, " | ") {
+		t.Errorf("glyph set on another logger not used: %q", got)
+	}
+}
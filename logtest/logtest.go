@@ -0,0 +1,28 @@
+// Package logtest attaches the log lines of the code under test to the
+// running test, so they are only shown when it fails or runs verbosely.
+//
+//	func TestHandler(t *testing.T) {
+//		logtest.SetOutput(t)
+//		...
+//	}
+//
+// It is a testing.TB flavored front end to SetTestOutput.
+package logtest
+
+import (
+	"testing"
+
+	log "github.com/kermitbu/gant-log"
+)
+
+// SetOutput routes the lines of the package level logger to t.Log. The
+// previous output is restored when the test finishes.
+func SetOutput(t testing.TB) {
+	log.SetTestOutput(t)
+}
+
+// SetLoggerOutput routes the lines of l to t.Log. The previous output is
+// restored when the test finishes.
+func SetLoggerOutput(t testing.TB, l *log.QLogger) {
+	l.SetTestOutput(t)
+}
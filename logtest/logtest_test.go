@@ -0,0 +1,64 @@
+package logtest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	log "github.com/kermitbu/gant-log"
+)
+
+// fakeTB records the lines logged to it and the cleanups registered
+type fakeTB struct {
+	testing.TB
+	lines    []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Log(args ...interface{}) {
+	for _, a := range args {
+		f.lines = append(f.lines, a.(string))
+	}
+}
+
+func (f *fakeTB) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func (f *fakeTB) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestSetLoggerOutput(t *testing.T) {
+	l := log.New()
+	l.SetColorMode(log.ColorNever)
+	var prev bytes.Buffer
+	l.SetOutput(&prev)
+
+	tb := &fakeTB{}
+	SetLoggerOutput(tb, l)
+	l.Error("first")
+	l.Error("second")
+
+	if len(tb.lines) != 2 {
+		t.Fatalf("t.Log got %d lines, want one per record: %q", len(tb.lines), tb.lines)
+	}
+	for i, want := range []string{"first", "second"} {
+		if line := tb.lines[i]; !strings.HasSuffix(line, want) {
+			t.Errorf("line %d = %q, want suffix %q", i, line, want)
+		}
+	}
+	if prev.Len() != 0 {
+		t.Errorf("previous output got %q while redirected", prev.String())
+	}
+
+	tb.finish()
+	l.Error("restored")
+	if !strings.Contains(prev.String(), "restored") {
+		t.Errorf("previous output not restored, got %q", prev.String())
+	}
+}
@@ -0,0 +1,124 @@
+package log
+
+import "sort"
+
+// Field is one key/value pair of OrderedFields
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedFields are record fields that keep their order in the output, for
+// when the order carries meaning to a human reader. Fields added by the
+// logger, e.g. from the context, follow them in key order. A repeated key
+// keeps its first position and its last value.
+type OrderedFields []Field
+
+// options converts the fields to call options
+func (f OrderedFields) options() callOptions {
+	if len(f) == 0 {
+		return callOptions{}
+	}
+	fields := make(map[string]interface{}, len(f))
+	order := make([]string, 0, len(f))
+	for _, field := range f {
+		if _, ok := fields[field.Key]; !ok {
+			order = append(order, field.Key)
+		}
+		fields[field.Key] = field.Value
+	}
+	return callOptions{fields: fields, order: order}
+}
+
+// orderedKeys returns the field keys of record, the ordered ones first
+func orderedKeys(record LogRecord) []string {
+	if len(record.order) == 0 {
+		return sortedKeys(record.Fields)
+	}
+	keys := make([]string, 0, len(record.Fields))
+	listed := make(map[string]bool, len(record.order))
+	for _, key := range record.order {
+		if _, ok := record.Fields[key]; ok && !listed[key] {
+			keys = append(keys, key)
+			listed[key] = true
+		}
+	}
+	rest := len(keys)
+	for key := range record.Fields {
+		if !listed[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys[rest:])
+	return keys
+}
+
+// FieldLogger logs records carrying a fixed set of ordered fields
+type FieldLogger struct {
+	opts callOptions
+}
+
+// WithFields returns a logger adding fields to every record
+func WithFields(fields OrderedFields) *FieldLogger {
+	return &FieldLogger{opts: fields.options()}
+}
+
+// Debug logs a DEBUG record with the fields
+func (f *FieldLogger) Debug(format string, v ...interface{}) {
+	log.mustLogWith(f.opts, LevelDebug, 2, format, v...)
+}
+
+// Info logs an INFO record with the fields
+func (f *FieldLogger) Info(format string, v ...interface{}) {
+	log.mustLogWith(f.opts, LevelInfo, 2, format, v...)
+}
+
+// Warn logs a WARN record with the fields
+func (f *FieldLogger) Warn(format string, v ...interface{}) {
+	log.mustLogWith(f.opts, LevelWarn, 2, format, v...)
+}
+
+// Error logs an ERROR record with the fields
+func (f *FieldLogger) Error(format string, v ...interface{}) {
+	log.mustLogWith(f.opts, LevelError, 2, format, v...)
+}
+
+// Debugw logs a DEBUG record with msg and the ordered fields
+func (l *QLogger) Debugw(msg string, fields OrderedFields) {
+	l.mustLogWith(fields.options(), LevelDebug, 2, "%s", msg)
+}
+
+// Infow logs an INFO record with msg and the ordered fields
+func (l *QLogger) Infow(msg string, fields OrderedFields) {
+	l.mustLogWith(fields.options(), LevelInfo, 2, "%s", msg)
+}
+
+// Warnw logs a WARN record with msg and the ordered fields
+func (l *QLogger) Warnw(msg string, fields OrderedFields) {
+	l.mustLogWith(fields.options(), LevelWarn, 2, "%s", msg)
+}
+
+// Errorw logs an ERROR record with msg and the ordered fields
+func (l *QLogger) Errorw(msg string, fields OrderedFields) {
+	l.mustLogWith(fields.options(), LevelError, 2, "%s", msg)
+}
+
+// Debugw 输出一条带有按顺序排列的字段的 DEBUG 日志
+func Debugw(msg string, fields OrderedFields) {
+	log.mustLogWith(fields.options(), LevelDebug, 2, "%s", msg)
+}
+
+// Infow 输出一条带有按顺序排列的字段的 INFO 日志
+func Infow(msg string, fields OrderedFields) {
+	log.mustLogWith(fields.options(), LevelInfo, 2, "%s", msg)
+}
+
+// Warnw 输出一条带有按顺序排列的字段的 WARN 日志
+func Warnw(msg string, fields OrderedFields) {
+	log.mustLogWith(fields.options(), LevelWarn, 2, "%s", msg)
+}
+
+// Errorw 输出一条带有按顺序排列的字段的 ERROR 日志
+func Errorw(msg string, fields OrderedFields) {
+	log.mustLogWith(fields.options(), LevelError, 2, "%s", msg)
+}
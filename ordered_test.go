@@ -0,0 +1,32 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestOrderedFields(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.Errorw("retrying", OrderedFields{{Key: "step", Value: 3}, {Key: "attempt", Value: 1}, {Key: "step", Value: 4}})
+	if got := buf.String(); !strings.HasSuffix(got, "retrying step=4 attempt=1\n") {
+		t.Errorf("text line = %q", got)
+	}
+
+	// Fields added by the logger follow the ordered ones in key order
+	buf.Reset()
+	l.SetFormat(FormatJSON)
+	opts := OrderedFields{{Key: "zeta", Value: 1}, {Key: "alpha", Value: 2}}.options()
+	opts.ctx = ContextWithFields(context.Background(), map[string]interface{}{"b_ctx": 3, "a_ctx": 4})
+	l.mustLogWith(opts, LevelError, 1, "ordered")
+	if got := buf.String(); !strings.HasSuffix(got, `"zeta":1,"alpha":2,"a_ctx":4,"b_ctx":3}`+"\n") {
+		t.Errorf("JSON line = %q", got)
+	}
+}
+
+func TestOrderedKeysWithoutOrder(t *testing.T) {
+	record := LogRecord{Fields: map[string]interface{}{"c": 1, "a": 2, "b": 3}}
+	if got := strings.Join(orderedKeys(record), ","); got != "a,b,c" {
+		t.Errorf("keys = %s, want sorted", got)
+	}
+}
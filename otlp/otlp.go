@@ -0,0 +1,334 @@
+// Package otlp ships log records to an OpenTelemetry collector using the
+// OTLP/HTTP protocol with JSON encoding, which only needs the standard
+// library. Register the exporter as a hook:
+//
+//	exp := otlp.New("http://localhost:4318/v1/logs", "my-service")
+//	log.AddHook(exp.Hook)
+//	defer exp.Close()
+package otlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/kermitbu/gant-log"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = time.Second
+	defaultMaxRetries    = 3
+	defaultRetryBackoff  = 200 * time.Millisecond
+	defaultMaxQueued     = 10000
+)
+
+// Exporter batches log records and posts them to an OTLP/HTTP endpoint,
+// retrying failed requests with an exponential backoff. At most MaxQueued
+// records wait to be sent, so an unreachable collector costs bounded
+// memory; the records beyond it are dropped and counted by Dropped.
+type Exporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+
+	BatchSize     int
+	FlushInterval time.Duration
+	MaxRetries    int
+	RetryBackoff  time.Duration
+	MaxQueued     int
+
+	mu      sync.Mutex
+	batch   []logRecord
+	dropped uint64
+	started bool
+	closed  bool
+	kick    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+
+	// settings copied from the exported fields by start
+	batchSize     int
+	flushInterval time.Duration
+	maxQueued     int
+}
+
+// New returns an exporter posting to endpoint, usually
+// http://<collector>:4318/v1/logs, with service as the service.name
+// resource attribute. The exported fields can be changed before the first
+// record is handled; a BatchSize, FlushInterval or MaxQueued of 0 or less
+// selects the default.
+func New(endpoint, service string) *Exporter {
+	return &Exporter{
+		endpoint:      endpoint,
+		service:       service,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		BatchSize:     defaultBatchSize,
+		FlushInterval: defaultFlushInterval,
+		MaxRetries:    defaultMaxRetries,
+		RetryBackoff:  defaultRetryBackoff,
+		MaxQueued:     defaultMaxQueued,
+	}
+}
+
+// Hook converts the record and queues it for the next batch. It has the
+// signature of log.Hook. Records handled after Close are dropped.
+func (e *Exporter) Hook(level int, record log.LogRecord) {
+	r := convert(level, record)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		e.dropped++
+		return
+	}
+	if !e.started {
+		e.start()
+	}
+	if len(e.batch) >= e.maxQueued {
+		e.dropped++
+		return
+	}
+	e.batch = append(e.batch, r)
+	if len(e.batch) >= e.batchSize {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Dropped returns how many records were dropped because the queue was full
+// or the exporter was closed
+func (e *Exporter) Dropped() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dropped
+}
+
+// Close sends the pending records and stops the exporter
+func (e *Exporter) Close() error {
+	e.mu.Lock()
+	if e.closed || !e.started {
+		e.closed = true
+		e.mu.Unlock()
+		return nil
+	}
+	e.closed = true
+	stop, done := e.stop, e.done
+	e.mu.Unlock()
+
+	close(stop)
+	<-done
+	return nil
+}
+
+// start validates the settings and launches the sender goroutine. It must
+// be called with e.mu held.
+func (e *Exporter) start() {
+	e.batchSize = orDefault(e.BatchSize, defaultBatchSize)
+	e.maxQueued = orDefault(e.MaxQueued, defaultMaxQueued)
+	e.flushInterval = e.FlushInterval
+	if e.flushInterval <= 0 {
+		e.flushInterval = defaultFlushInterval
+	}
+	e.started = true
+	e.kick = make(chan struct{}, 1)
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	go e.run(e.kick, e.stop, e.done)
+}
+
+func orDefault(n, def int) int {
+	if n <= 0 {
+		return def
+	}
+	return n
+}
+
+func (e *Exporter) run(kick, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-kick:
+		case <-ticker.C:
+		case <-stop:
+			e.flush()
+			return
+		}
+		e.flush()
+	}
+}
+
+// flush sends the records queued so far in batches of BatchSize
+func (e *Exporter) flush() {
+	e.mu.Lock()
+	pending := e.batch
+	e.batch = nil
+	e.mu.Unlock()
+
+	for len(pending) > 0 {
+		n := len(pending)
+		if n > e.batchSize {
+			n = e.batchSize
+		}
+		e.send(pending[:n])
+		pending = pending[n:]
+	}
+}
+
+// send posts the batch, retrying on network errors, 429 and 5xx responses.
+// A batch that still fails is dropped.
+func (e *Exporter) send(batch []logRecord) {
+	body, err := json.Marshal(e.request(batch))
+	if err != nil {
+		return
+	}
+	backoff := e.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := e.post(body)
+		if err == nil || !retry || attempt >= e.MaxRetries {
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (e *Exporter) post(body []byte) (retry bool, err error) {
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode/100 == 2:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode/100 == 5:
+		return true, fmt.Errorf("otlp: %s", resp.Status)
+	default:
+		return false, fmt.Errorf("otlp: %s", resp.Status)
+	}
+}
+
+// Severity numbers defined by the OpenTelemetry log data model
+var severities = [...]struct {
+	number int
+	text   string
+}{
+	log.LevelDebug: {5, "DEBUG"},
+	log.LevelInfo:  {9, "INFO"},
+	log.LevelWarn:  {13, "WARN"},
+	log.LevelError: {17, "ERROR"},
+	log.LevelFatal: {21, "FATAL"},
+}
+
+type anyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type logRecord struct {
+	TimeUnixNano   string     `json:"timeUnixNano"`
+	SeverityNumber int        `json:"severityNumber"`
+	SeverityText   string     `json:"severityText"`
+	Body           anyValue   `json:"body"`
+	Attributes     []keyValue `json:"attributes,omitempty"`
+}
+
+type exportRequest struct {
+	ResourceLogs []resourceLogs `json:"resourceLogs"`
+}
+
+type resourceLogs struct {
+	Resource struct {
+		Attributes []keyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeLogs []scopeLogs `json:"scopeLogs"`
+}
+
+type scopeLogs struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	LogRecords []logRecord `json:"logRecords"`
+}
+
+func (e *Exporter) request(batch []logRecord) exportRequest {
+	var rl resourceLogs
+	rl.Resource.Attributes = []keyValue{{Key: "service.name", Value: value(e.service)}}
+	var sl scopeLogs
+	sl.Scope.Name = "github.com/kermitbu/gant-log"
+	sl.LogRecords = batch
+	rl.ScopeLogs = []scopeLogs{sl}
+	return exportRequest{ResourceLogs: []resourceLogs{rl}}
+}
+
+// convert maps a record to an OTLP log record. The caller, component,
+// event and fields become attributes.
+func convert(level int, record log.LogRecord) logRecord {
+	severity := severities[level]
+	r := logRecord{
+		TimeUnixNano:   strconv.FormatInt(record.Time.UnixNano(), 10),
+		SeverityNumber: severity.number,
+		SeverityText:   severity.text,
+		Body:           value(record.Message),
+	}
+	attr := func(key string, v interface{}) {
+		r.Attributes = append(r.Attributes, keyValue{Key: key, Value: value(v)})
+	}
+	if record.Filename != "" {
+		attr("code.filepath", record.Filename)
+		attr("code.lineno", record.LineNo)
+	}
+	if record.Function != "" {
+		attr("code.function", record.Function)
+	}
+	if record.Component != "" {
+		attr("component", record.Component)
+	}
+	if record.Event != "" {
+		attr("event.name", record.Event)
+	}
+	if record.Stack != "" {
+		attr("exception.stacktrace", record.Stack)
+	}
+	for key, v := range record.Fields {
+		attr(key, v)
+	}
+	return r
+}
+
+// value converts a field value to an OTLP AnyValue
+func value(v interface{}) anyValue {
+	switch v := v.(type) {
+	case string:
+		return anyValue{StringValue: &v}
+	case bool:
+		return anyValue{BoolValue: &v}
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		s := fmt.Sprint(v)
+		return anyValue{IntValue: &s}
+	case float32:
+		f := float64(v)
+		return anyValue{DoubleValue: &f}
+	case float64:
+		return anyValue{DoubleValue: &v}
+	default:
+		s := fmt.Sprint(v)
+		return anyValue{StringValue: &s}
+	}
+}
@@ -0,0 +1,166 @@
+package otlp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/kermitbu/gant-log"
+)
+
+// receiver is a mock collector recording the posted log records
+type receiver struct {
+	mu       sync.Mutex
+	requests int
+	records  []logRecord
+	service  string
+	failures int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests++
+	if rc.failures > 0 {
+		rc.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var req exportRequest
+	if r.Header.Get("Content-Type") != "application/json" || json.NewDecoder(r.Body).Decode(&req) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, rl := range req.ResourceLogs {
+		rc.service = *rl.Resource.Attributes[0].Value.StringValue
+		for _, sl := range rl.ScopeLogs {
+			rc.records = append(rc.records, sl.LogRecords...)
+		}
+	}
+}
+
+func newReceiver(t *testing.T) (*receiver, string) {
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	t.Cleanup(srv.Close)
+	return rc, srv.URL + "/v1/logs"
+}
+
+func record(msg string) log.LogRecord {
+	return log.LogRecord{
+		Time:     time.Unix(1717243200, 0),
+		Message:  msg,
+		Filename: "main.go",
+		LineNo:   7,
+		Fields:   map[string]interface{}{"user": "bob", "attempt": 2},
+	}
+}
+
+func TestExporterBatches(t *testing.T) {
+	rc, endpoint := newReceiver(t)
+	e := New(endpoint, "billing")
+	e.BatchSize = 2
+	e.FlushInterval = time.Hour
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		e.Hook(log.LevelWarn, record(msg))
+	}
+	e.Close()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.records) != 5 || rc.requests < 3 {
+		t.Fatalf("got %d records in %d requests, want 5 in at least 3", len(rc.records), rc.requests)
+	}
+	if rc.service != "billing" {
+		t.Errorf("service.name = %q", rc.service)
+	}
+	r := rc.records[0]
+	if r.SeverityNumber != 13 || r.SeverityText != "WARN" || *r.Body.StringValue != "a" || r.TimeUnixNano != "1717243200000000000" {
+		t.Errorf("record = %+v", r)
+	}
+	attrs := map[string]anyValue{}
+	for _, kv := range r.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if v := attrs["user"].StringValue; v == nil || *v != "bob" {
+		t.Errorf("user attribute = %+v", attrs["user"])
+	}
+	if v := attrs["attempt"].IntValue; v == nil || *v != "2" {
+		t.Errorf("attempt attribute = %+v", attrs["attempt"])
+	}
+	if v := attrs["code.lineno"].IntValue; v == nil || *v != "7" {
+		t.Errorf("code.lineno attribute = %+v", attrs["code.lineno"])
+	}
+}
+
+func TestExporterRetries(t *testing.T) {
+	rc, endpoint := newReceiver(t)
+	rc.failures = 2
+	e := New(endpoint, "billing")
+	e.RetryBackoff = time.Millisecond
+	e.Hook(log.LevelError, record("retried"))
+	e.Close()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.requests != 3 || len(rc.records) != 1 {
+		t.Errorf("got %d records in %d requests, want 1 in 3", len(rc.records), rc.requests)
+	}
+}
+
+func TestExporterQueueCap(t *testing.T) {
+	rc, endpoint := newReceiver(t)
+	e := New(endpoint, "billing")
+	e.FlushInterval = time.Hour
+	e.MaxQueued = 3
+	for i := 0; i < 5; i++ {
+		e.Hook(log.LevelInfo, record("queued"))
+	}
+	if n := e.Dropped(); n != 2 {
+		t.Errorf("dropped %d records over the cap, want 2", n)
+	}
+	e.Close()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.records) != 3 {
+		t.Errorf("sent %d records, want 3", len(rc.records))
+	}
+}
+
+func TestExporterHookAfterClose(t *testing.T) {
+	rc, endpoint := newReceiver(t)
+	e := New(endpoint, "billing")
+	e.Hook(log.LevelInfo, record("before"))
+	e.Close()
+	e.Hook(log.LevelInfo, record("after"))
+	e.Close()
+
+	if n := e.Dropped(); n != 1 {
+		t.Errorf("dropped %d records after Close, want 1", n)
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.records) != 1 {
+		t.Errorf("sent %d records, want only the one before Close", len(rc.records))
+	}
+}
+
+func TestExporterInvalidSettings(t *testing.T) {
+	rc, endpoint := newReceiver(t)
+	e := New(endpoint, "billing")
+	e.BatchSize = 0
+	e.FlushInterval = 0
+	e.MaxQueued = -1
+	e.Hook(log.LevelInfo, record("defaults"))
+	e.Close()
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.records) != 1 {
+		t.Errorf("sent %d records, want 1", len(rc.records))
+	}
+}
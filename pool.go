@@ -0,0 +1,94 @@
+package log
+
+import "sync"
+
+var (
+	recordPool = sync.Pool{
+		New: func() interface{} { return new(LogRecord) },
+	}
+	fieldsPool = sync.Pool{
+		New: func() interface{} { return make(map[string]interface{}, 8) },
+	}
+)
+
+// SetRecordPooling reuses the record and field map storage between log
+// calls instead of allocating it for every record. Records are returned to
+// the pool once every output and synchronous hook consumed them; field maps
+// that were queued for async hooks are never reused. Hooks that keep a
+// record after returning must copy its Fields while pooling is on.
+func (l *QLogger) SetRecordPooling(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pooling = on
+}
+
+// fieldBuilder adds the logger annotations to the fields passed by the
+// caller. The caller map is never modified: it is copied into a new, possibly
+// pooled, map on the first added field.
+type fieldBuilder struct {
+	base   map[string]interface{}
+	merged map[string]interface{}
+	pooled bool
+}
+
+func (b *fieldBuilder) set(key string, value interface{}) {
+	if b.merged == nil {
+		if b.pooled {
+			b.merged = fieldsPool.Get().(map[string]interface{})
+		} else {
+			b.merged = make(map[string]interface{}, len(b.base)+2)
+		}
+		for k, v := range b.base {
+			b.merged[k] = v
+		}
+	}
+	b.merged[key] = value
+}
+
+func (b *fieldBuilder) has(key string) bool {
+	_, ok := b.fields()[key]
+	return ok
+}
+
+func (b *fieldBuilder) fields() map[string]interface{} {
+	if b.merged != nil {
+		return b.merged
+	}
+	return b.base
+}
+
+// release returns the merged map to the pool unless it is still referenced
+func (b *fieldBuilder) release(held bool) {
+	if !b.pooled || held || b.merged == nil {
+		return
+	}
+	for k := range b.merged {
+		delete(b.merged, k)
+	}
+	fieldsPool.Put(b.merged)
+	b.merged = nil
+}
+
+// newRecord returns zeroed record storage. It must be called with l.mu held.
+func (l *QLogger) newRecord() *LogRecord {
+	if l.pooling {
+		return recordPool.Get().(*LogRecord)
+	}
+	return new(LogRecord)
+}
+
+// releaseRecord hands record and its fields back to the pools. It must be
+// called with l.mu held.
+func (l *QLogger) releaseRecord(record *LogRecord, fields *fieldBuilder) {
+	if !l.pooling {
+		return
+	}
+	fields.release(l.hookPool != nil && len(l.hooks) > 0)
+	*record = LogRecord{}
+	recordPool.Put(record)
+}
+
+// SetRecordPooling 开启后复用日志记录和字段的内存，减少每次输出的内存分配
+func SetRecordPooling(on bool) {
+	log.SetRecordPooling(on)
+}
@@ -0,0 +1,64 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestRecordPoolingAsyncHooks(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	l.SetOutput(io.Discard)
+	l.SetRecordPooling(true)
+
+	var mu sync.Mutex
+	mismatches := 0
+	l.AddHook(func(level int, record LogRecord) {
+		if fmt.Sprint(record.Fields["n"]) != record.Message {
+			mu.Lock()
+			mismatches++
+			mu.Unlock()
+		}
+	})
+	l.SetAsyncHooks(4, 64, OverflowBlock)
+	t.Cleanup(func() { l.SetAsyncHooks(0, 0, OverflowDrop) })
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				n := g*1000 + i
+				ctx := ContextWithFields(context.Background(), map[string]interface{}{"n": n})
+				l.ErrorContext(ctx, "%d", n)
+			}
+		}(g)
+	}
+	wg.Wait()
+	l.Barrier()
+
+	if mismatches > 0 {
+		t.Errorf("%d hook records saw the fields of another record", mismatches)
+	}
+}
+
+// BenchmarkRecordPooling logs records with context fields, which need a
+// merged field map, with and without pooling
+func BenchmarkRecordPooling(b *testing.B) {
+	ctx := ContextWithFields(context.Background(), map[string]interface{}{"request_id": "r-1"})
+	for _, pooling := range []bool{false, true} {
+		b.Run(fmt.Sprintf("pooling=%t", pooling), func(b *testing.B) {
+			l := New()
+			l.SetOutput(io.Discard)
+			l.SetColorMode(ColorNever)
+			l.SetRecordPooling(pooling)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.ErrorContext(ctx, "request done")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package log
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+// DropRateLimited counts the records dropped by SetCallerRateLimit
+const DropRateLimited = "rate_limited"
+
+// callerLimit throttles each log call site separately
+type callerLimit struct {
+	limit  int
+	window time.Duration
+	sites  map[uintptr]*siteWindow
+}
+
+// siteWindow counts the records of one call site in the current window
+type siteWindow struct {
+	start  time.Time
+	count  int
+	warned bool
+}
+
+// SetCallerRateLimit drops the records of a call site beyond limit per
+// window, so one runaway site is throttled without affecting the others.
+// Sites are told apart by their program counter; the first time a site is
+// throttled a WARN naming it is logged. Records logged without a caller,
+// e.g. by the NoCaller variants, are not limited. A limit of zero disables
+// the limit.
+func (l *QLogger) SetCallerRateLimit(limit int, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if limit <= 0 || window <= 0 {
+		l.callerLimit = nil
+		return
+	}
+	l.callerLimit = &callerLimit{
+		limit:  limit,
+		window: window,
+		sites:  make(map[uintptr]*siteWindow),
+	}
+}
+
+// allowCaller reports whether the call site at pc may log another record.
+// It must be called with l.mu held.
+func (l *QLogger) allowCaller(pc uintptr, now time.Time, function, file string, line int) bool {
+	c := l.callerLimit
+	site := c.sites[pc]
+	if site == nil {
+		site = &siteWindow{start: now}
+		c.sites[pc] = site
+	}
+	if now.Sub(site.start) >= c.window {
+		site.start, site.count = now, 0
+	}
+	site.count++
+	if site.count <= c.limit {
+		return true
+	}
+
+	drops.add(DropRateLimited)
+	if !site.warned {
+		site.warned = true
+		l.warnThrottled(now, function, file, line)
+	}
+	return false
+}
+
+// warnThrottled writes a WARN record naming the throttled call site. It must
+// be called with l.mu held.
+func (l *QLogger) warnThrottled(now time.Time, function, file string, line int) {
+	if !levelEnabled("", LevelWarn) {
+		return
+	}
+	name := filepath.Base(file)
+	c := l.callerLimit
+	message := fmt.Sprintf("log site %s:%d exceeded %d records per %s, dropping its records",
+		name, line, c.limit, c.window)
+	l.emit(LevelWarn, LogRecord{
+		Time:     now,
+		Level:    getLevelTag(LevelWarn),
+		Message:  message,
+		Filename: name,
+		LineNo:   line,
+		Function: function,
+		Caller:   l.formatCaller(function, name, line),
+	})
+}
+
+// SetCallerRateLimit 限制同一个调用位置在 window 时间内最多输出 limit 条日志，超出的日志被丢弃
+func SetCallerRateLimit(limit int, window time.Duration) {
+	log.SetCallerRateLimit(limit, window)
+}
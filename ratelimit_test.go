@@ -0,0 +1,44 @@
+package log
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCallerRateLimit(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	clock := &fakeClock{t: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	l.SetClock(clock.now)
+	l.SetCallerRateLimit(2, time.Second)
+
+	before := drops.get(DropRateLimited)
+	burst := func() {
+		for i := 0; i < 5; i++ {
+			l.Error("runaway %d", i)
+		}
+	}
+	burst()
+	l.Error("other site")
+
+	got := buf.String()
+	if n := strings.Count(got, "runaway"); n != 2 {
+		t.Errorf("runaway site wrote %d records, want 2", n)
+	}
+	if n := strings.Count(got, "exceeded 2 records per 1s"); n != 1 {
+		t.Errorf("got %d throttle warnings, want 1: %q", n, got)
+	}
+	if !strings.Contains(got, "other site") {
+		t.Error("other call site throttled")
+	}
+	if n := drops.get(DropRateLimited) - before; n != 3 {
+		t.Errorf("counted %d rate limited records, want 3", n)
+	}
+
+	buf.Reset()
+	clock.set(clock.now().Add(time.Second))
+	burst()
+	if n := strings.Count(buf.String(), "runaway"); n != 2 {
+		t.Errorf("runaway site wrote %d records in the next window, want 2", n)
+	}
+}
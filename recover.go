@@ -0,0 +1,142 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// defaultPanicStackBytes bounds the stack logged by Recover
+const defaultPanicStackBytes = 64 << 10
+
+var (
+	panicMu         sync.Mutex
+	panicStackAll   bool
+	panicStackBytes = defaultPanicStackBytes
+)
+
+// SetPanicStack sets what Recover logs: the stack of the panicking goroutine
+// only or of all goroutines, capped to maxBytes. The default is the
+// panicking goroutine capped to 64 KiB. A maxBytes of 0 or less restores the
+// default cap.
+func SetPanicStack(all bool, maxBytes int) {
+	if maxBytes <= 0 {
+		maxBytes = defaultPanicStackBytes
+	}
+	panicMu.Lock()
+	defer panicMu.Unlock()
+	panicStackAll = all
+	panicStackBytes = maxBytes
+}
+
+// Recover logs a recovered panic at ERROR together with the stack. It must
+// be deferred directly: defer l.Recover()
+//
+// A panic value that is an error is also logged as the panic_error field,
+// with the innermost wrapped error as panic_cause. The fields of a value, or
+// of an error in its chain, with a Fields() map[string]interface{} method
+// are added to the record.
+func (l *QLogger) Recover() {
+	if v := recover(); v != nil {
+		l.logPanic(v)
+	}
+}
+
+// logPanic logs v with the stack of the goroutine that panicked
+func (l *QLogger) logPanic(v interface{}) {
+	opts := callOptions{fields: panicFields(v)}
+	if frame, ok := panicFrame(); ok {
+		opts.frame = &frame
+	}
+	l.mustLogWith(opts, LevelError, 2, "panic: %v\n%s", v, panicStack())
+}
+
+// fielder is implemented by panic values carrying structured details
+type fielder interface {
+	Fields() map[string]interface{}
+}
+
+// panicFields returns the fields describing the panic value v
+func panicFields(v interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if f, ok := v.(fielder); ok {
+		for k, fv := range f.Fields() {
+			fields[k] = fv
+		}
+	}
+	if err, ok := v.(error); ok {
+		fields["panic_error"] = err.Error()
+		for inner := errors.Unwrap(err); inner != nil; inner = errors.Unwrap(inner) {
+			if f, ok := inner.(fielder); ok {
+				for k, fv := range f.Fields() {
+					if _, exists := fields[k]; !exists {
+						fields[k] = fv
+					}
+				}
+			}
+			fields["panic_cause"] = inner.Error()
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// panicFrame returns the frame of the function that panicked, the first one
+// outside the runtime below runtime.gopanic
+func panicFrame() (runtime.Frame, bool) {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame, true
+		}
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return runtime.Frame{}, false
+		}
+	}
+}
+
+// panicStack returns the stack configured by SetPanicStack without the
+// frames of the logger and the runtime panic handling. The panicking
+// goroutine is dumped first, so the tail is cut off when the cap is hit.
+func panicStack() []byte {
+	panicMu.Lock()
+	all, max := panicStackAll, panicStackBytes
+	panicMu.Unlock()
+
+	buf := make([]byte, max+4096)
+	buf = buf[:runtime.Stack(buf, all)]
+	if i := bytes.Index(buf, []byte("\npanic(")); i >= 0 {
+		header := buf[:bytes.IndexByte(buf, '\n')+1]
+		rest := buf[i+1:]
+		// Drop the panic function line and its file line
+		for n := 0; n < 2 && len(rest) > 0; n++ {
+			if j := bytes.IndexByte(rest, '\n'); j >= 0 {
+				rest = rest[j+1:]
+			} else {
+				rest = nil
+			}
+		}
+		buf = append(append([]byte{}, header...), rest...)
+	}
+	if len(buf) > max {
+		buf = append(buf[:max:max], "\n...truncated"...)
+	}
+	return bytes.TrimRight(buf, "\n")
+}
+
+// Recover 捕获 panic 并以 ERROR 级别输出 panic 信息和堆栈，需要直接 defer log.Recover()
+func Recover() {
+	if v := recover(); v != nil {
+		log.logPanic(v)
+	}
+}
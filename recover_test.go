@@ -0,0 +1,109 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// setPanicStack sets the panic stack options for the duration of the test
+func setPanicStack(t *testing.T, all bool, maxBytes int) {
+	t.Helper()
+	SetPanicStack(all, maxBytes)
+	t.Cleanup(func() { SetPanicStack(false, defaultPanicStackBytes) })
+}
+
+func explode(l *QLogger) {
+	defer l.Recover()
+	panic("boom")
+}
+
+func TestRecoverStackBounded(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+	setPanicStack(t, false, 4096)
+
+	explode(l)
+	if !strings.HasPrefix(last.Message, "panic: boom\n") {
+		t.Fatalf("message = %q", last.Message)
+	}
+	stack := strings.TrimPrefix(last.Message, "panic: boom\n")
+	if len(stack) > 4096+len("\n...truncated") {
+		t.Errorf("stack has %d bytes, cap is 4096", len(stack))
+	}
+	if !strings.Contains(stack, "log.explode") {
+		t.Errorf("stack lacks the panicking function:\n%s", stack)
+	}
+	if strings.Contains(stack, "\ngoroutine ") {
+		t.Errorf("stack of other goroutines logged:\n%s", stack)
+	}
+}
+
+func TestRecoverStackTruncated(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+	setPanicStack(t, true, 64)
+
+	explode(l)
+	if !strings.HasSuffix(last.Message, "\n...truncated") {
+		t.Errorf("message = %q, want a truncated stack", last.Message)
+	}
+}
+
+func TestSetPanicStackInvalidCap(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+	setPanicStack(t, false, -1)
+
+	explode(l)
+	if !strings.Contains(last.Message, "log.explode") {
+		t.Errorf("message = %q", last.Message)
+	}
+}
+
+// orderError is a panic value carrying structured details
+type orderError struct {
+	id int
+}
+
+func (e *orderError) Error() string { return fmt.Sprintf("order %d rejected", e.id) }
+
+func (e *orderError) Fields() map[string]interface{} {
+	return map[string]interface{}{"order_id": e.id}
+}
+
+func TestRecoverPanicFields(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+
+	func() {
+		defer l.Recover()
+		panic(fmt.Errorf("checkout: %w", &orderError{id: 42}))
+	}()
+	if got := last.Fields["panic_error"]; got != "checkout: order 42 rejected" {
+		t.Errorf("panic_error = %v", got)
+	}
+	if got := last.Fields["panic_cause"]; got != "order 42 rejected" {
+		t.Errorf("panic_cause = %v", got)
+	}
+	if got := last.Fields["order_id"]; got != 42 {
+		t.Errorf("order_id = %v", got)
+	}
+
+	func() {
+		defer l.Recover()
+		panic(errors.New("plain"))
+	}()
+	if _, ok := last.Fields["panic_cause"]; ok || last.Fields["panic_error"] != "plain" {
+		t.Errorf("fields of an unwrapped error = %v", last.Fields)
+	}
+
+	func() {
+		defer l.Recover()
+		panic("boom")
+	}()
+	if len(last.Fields) != 0 {
+		t.Errorf("fields of a string panic = %v", last.Fields)
+	}
+}
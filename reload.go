@@ -0,0 +1,114 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// Environment variables read by ReloadEnv
+const (
+	// EnvLevel holds a level policy in the SetLevelsFromString syntax,
+	// e.g. "info,db=debug"
+	EnvLevel = "IIGSLEVEL"
+	// EnvFormat holds the format of the primary output: text, json, auto or
+	// table
+	EnvFormat = "IIGSFORMAT"
+	// EnvColor holds the color mode of the primary output: always, auto or
+	// never
+	EnvColor = "IIGSCOLOR"
+)
+
+var hupOnce sync.Once
+
+// ReloadEnv applies the settings found in the environment: the level
+// policy from IIGSLEVEL and the format and color mode of the primary output
+// from IIGSFORMAT and IIGSCOLOR. Unset variables leave their setting
+// unchanged, and nothing is applied if any value is invalid. IIGSDEBUG is
+// only read at startup.
+func (l *QLogger) ReloadEnv() error {
+	format, formatSet := FormatText, false
+	if v := os.Getenv(EnvFormat); v != "" {
+		f, err := parseFormat(v)
+		if err != nil {
+			return err
+		}
+		format, formatSet = f, true
+	}
+	color, colorSet := ColorAlways, false
+	if v := os.Getenv(EnvColor); v != "" {
+		c, err := parseColorMode(v)
+		if err != nil {
+			return err
+		}
+		color, colorSet = c, true
+	}
+
+	if spec := os.Getenv(EnvLevel); spec != "" {
+		if err := SetLevelsFromString(spec); err != nil {
+			return fmt.Errorf("logger: %s: %v", EnvLevel, err)
+		}
+	}
+	if formatSet {
+		l.SetFormat(format)
+	}
+	if colorSet {
+		l.SetColorMode(color)
+	}
+	return nil
+}
+
+func parseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	case "auto":
+		return FormatAuto, nil
+	case "table":
+		return FormatTable, nil
+	default:
+		return 0, fmt.Errorf("logger: %s: unknown format %q", EnvFormat, s)
+	}
+}
+
+func parseColorMode(s string) (ColorMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "always":
+		return ColorAlways, nil
+	case "auto":
+		return ColorAuto, nil
+	case "never":
+		return ColorNever, nil
+	default:
+		return 0, fmt.Errorf("logger: %s: unknown color mode %q", EnvColor, s)
+	}
+}
+
+// InstallEnvReloadOnHUP calls ReloadEnv on the package level logger every
+// time the process receives SIGHUP, logging the outcome. Installing it
+// more than once has no effect.
+func InstallEnvReloadOnHUP() {
+	hupOnce.Do(func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		go func() {
+			for range hup {
+				if err := log.ReloadEnv(); err != nil {
+					log.mustLog(LevelWarn, 1, "reloading the environment: %v", err)
+				} else {
+					log.mustLog(LevelInfo, 1, "reloaded logging settings from the environment")
+				}
+			}
+		}()
+	})
+}
+
+// ReloadEnv 重新读取环境变量 IIGSLEVEL、IIGSFORMAT 和 IIGSCOLOR 并立即生效
+func ReloadEnv() error {
+	return log.ReloadEnv()
+}
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadEnv(t *testing.T) {
+	setLevel(t, LevelError)
+	resetComponentLevels(t)
+	l, _ := newBufferLogger(t)
+
+	t.Setenv(EnvLevel, "debug,db=warn")
+	t.Setenv(EnvFormat, "json")
+	t.Setenv(EnvColor, "always")
+	if err := l.ReloadEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != LevelDebug {
+		t.Errorf("level = %d, want DEBUG", GetLevel())
+	}
+	if l.primary.format != FormatJSON || l.primary.color != ColorAlways {
+		t.Errorf("format = %v, color = %v", l.primary.format, l.primary.color)
+	}
+
+	// An invalid value applies nothing
+	t.Setenv(EnvLevel, "info")
+	t.Setenv(EnvFormat, "text")
+	t.Setenv(EnvColor, "sometimes")
+	if err := l.ReloadEnv(); err == nil || !strings.Contains(err.Error(), EnvColor) {
+		t.Errorf("err = %v, want an %s error", err, EnvColor)
+	}
+	if GetLevel() != LevelDebug || l.primary.format != FormatJSON {
+		t.Errorf("invalid environment applied: level %d, format %v", GetLevel(), l.primary.format)
+	}
+
+	// Unset variables leave their setting unchanged
+	os.Unsetenv(EnvLevel)
+	os.Unsetenv(EnvColor)
+	t.Setenv(EnvFormat, "table")
+	if err := l.ReloadEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if GetLevel() != LevelDebug || l.primary.format != FormatTable || l.primary.color != ColorAlways {
+		t.Errorf("level %d, format %v, color %v", GetLevel(), l.primary.format, l.primary.color)
+	}
+}
+
+func TestEnvReloadOnHUP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no SIGHUP on windows")
+	}
+	setLevel(t, LevelError)
+	resetComponentLevels(t)
+	var buf syncBuffer
+	prev := log.Output()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(prev) })
+
+	InstallEnvReloadOnHUP()
+	InstallEnvReloadOnHUP()
+	t.Setenv(EnvLevel, "info")
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := self.Signal(syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(buf.String(), "reloaded logging settings") {
+		if time.Now().After(deadline) {
+			t.Fatalf("no reload after SIGHUP, output %q", buf.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if GetLevel() != LevelInfo {
+		t.Errorf("level = %d after SIGHUP, want INFO", GetLevel())
+	}
+	if n := strings.Count(buf.String(), "reloaded"); n != 1 {
+		t.Errorf("%d reloads for one SIGHUP", n)
+	}
+}
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"time"
+)
+
+// LogAt logs a record with the given timestamp instead of the current time,
+// e.g. to re-emit historical records
+func (l *QLogger) LogAt(t time.Time, level int, format string, v ...interface{}) {
+	l.mustLogWith(callOptions{time: t}, level, 2, format, v...)
+}
+
+// Replay reads NDJSON records produced by the JSON format and re-emits them
+// through the current outputs and hooks, keeping their original timestamps,
+// levels, callers and fields. It stops at the first malformed record.
+func (l *QLogger) Replay(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for n := 1; ; n++ {
+		var obj map[string]interface{}
+		if err := dec.Decode(&obj); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("logger: replay record %d: %v", n, err)
+		}
+		if err := l.replayRecord(obj); err != nil {
+			return fmt.Errorf("logger: replay record %d: %v", n, err)
+		}
+	}
+}
+
+func (l *QLogger) replayRecord(obj map[string]interface{}) error {
+	take := func(key string) string {
+		key = fieldName(key)
+		v, _ := obj[key].(string)
+		delete(obj, key)
+		return v
+	}
+
+	var opts callOptions
+	t, err := time.Parse(time.RFC3339Nano, take("time"))
+	if err != nil {
+		return err
+	}
+	opts.time = t
+	level, err := ParseLevel(take("level"))
+	if err != nil {
+		return err
+	}
+	message := take("msg")
+	opts.component = take("component")
+	opts.event = take("event")
+	opts.stack = take("stack")
+
+	file := take("file")
+	line, _ := obj[fieldName("line")].(json.Number)
+	delete(obj, fieldName("line"))
+	if file != "" {
+		n, _ := line.Int64()
+		opts.frame = &runtime.Frame{File: file, Line: int(n)}
+	} else {
+		opts.noCaller = true
+	}
+
+	if len(obj) > 0 {
+		opts.fields = obj
+	}
+	l.mustLogWith(opts, level, 0, "%s", message)
+	return nil
+}
+
+// LogAt 以指定的时间输出一条日志，用于重新输出历史日志
+func LogAt(t time.Time, level int, format string, v ...interface{}) {
+	log.mustLogWith(callOptions{time: t}, level, 2, format, v...)
+}
+
+// Replay 读取 JSON 格式输出的日志，按原有的时间和字段重新输出到当前的输出目标
+func Replay(r io.Reader) error {
+	return log.Replay(r)
+}
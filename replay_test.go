@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplayRoundTrip(t *testing.T) {
+	setLevel(t, LevelInfo)
+	src, out := newBufferLogger(t)
+	src.SetFormat(FormatJSON)
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	src.SetClock(func() time.Time { return at })
+	src.SetAutoStackLevel(LevelError)
+	src.Warnw("cache miss", OrderedFields{{Key: "key", Value: "user:1"}, {Key: "hits", Value: 3}})
+	src.Error("query failed")
+
+	dst, _ := newBufferLogger(t)
+	var records []LogRecord
+	var levels []int
+	dst.AddHook(func(level int, record LogRecord) {
+		levels = append(levels, level)
+		records = append(records, record)
+	})
+	if err := dst.Replay(bytes.NewReader(out.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("replayed %d records, want 2", len(records))
+	}
+	first, second := records[0], records[1]
+	if levels[0] != LevelWarn || first.Message != "cache miss" || !first.Time.Equal(at) {
+		t.Errorf("first record = %d %q at %v", levels[0], first.Message, first.Time)
+	}
+	if first.Fields["key"] != "user:1" || first.Fields["hits"] == nil {
+		t.Errorf("first record fields = %v", first.Fields)
+	}
+	if levels[1] != LevelError || !strings.Contains(second.Stack, "TestReplayRoundTrip") {
+		t.Errorf("second record = %d with stack %q", levels[1], second.Stack)
+	}
+}
+
+func TestReplayMalformed(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	err := l.Replay(strings.NewReader(`{"time":"2024-06-01T12:00:00Z","level":"INFO","msg":"ok"}` + "\n{broken\n"))
+	if err == nil || !strings.Contains(err.Error(), "replay record 2") {
+		t.Errorf("error = %v", err)
+	}
+}
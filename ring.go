@@ -0,0 +1,162 @@
+package log
+
+import (
+	"io"
+	"sync"
+)
+
+// tailBuffer is the number of lines a TailRing follower may fall behind
+// before new lines are dropped for it
+const tailBuffer = 256
+
+// recordRing keeps the most recent records in memory, bounded by the
+// number of entries and optionally by their estimated size
+type recordRing struct {
+	entries []LogRecord
+	start   int
+	count   int
+	bytes   int
+	budget  int
+	tails   map[chan LogRecord]struct{}
+}
+
+// SetRingBuffer keeps the last size records in memory for TailRing. Zero
+// disables the buffer and drops the records it held.
+func (l *QLogger) SetRingBuffer(size int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := l.recordRing()
+	old := r.snapshot()
+	r.entries, r.start, r.count, r.bytes = nil, 0, 0, 0
+	if size > 0 {
+		r.entries = make([]LogRecord, size)
+		if len(old) > size {
+			old = old[len(old)-size:]
+		}
+		for _, record := range old {
+			r.add(record)
+		}
+	}
+}
+
+// recordRing returns the ring, creating it on first use. It must be called
+// with l.mu held.
+func (l *QLogger) recordRing() *recordRing {
+	if l.ring == nil {
+		l.ring = &recordRing{tails: make(map[chan LogRecord]struct{})}
+	}
+	return l.ring
+}
+
+// retain stores the record in the ring buffer and passes it to the
+// followers. It must be called with l.mu held.
+func (l *QLogger) retain(record LogRecord) {
+	r := l.ring
+	if r == nil {
+		return
+	}
+	if l.pooling && record.Fields != nil {
+		fields := make(map[string]interface{}, len(record.Fields))
+		for k, v := range record.Fields {
+			fields[k] = v
+		}
+		record.Fields = fields
+	}
+	if len(r.entries) > 0 {
+		r.add(record)
+	}
+	for tail := range r.tails {
+		select {
+		case tail <- record:
+		default:
+		}
+	}
+}
+
+func (r *recordRing) add(record LogRecord) {
+	if r.count == len(r.entries) {
+		r.evict()
+	}
+	r.entries[(r.start+r.count)%len(r.entries)] = record
+	r.count++
+	r.bytes += recordSize(record)
+	r.trim()
+}
+
+// evict drops the oldest record
+func (r *recordRing) evict() {
+	r.bytes -= recordSize(r.entries[r.start])
+	r.entries[r.start] = LogRecord{}
+	r.start = (r.start + 1) % len(r.entries)
+	r.count--
+}
+
+// trim evicts the oldest records until the ring fits its budget, always
+// keeping the newest one
+func (r *recordRing) trim() {
+	for r.budget > 0 && r.bytes > r.budget && r.count > 1 {
+		r.evict()
+	}
+}
+
+// snapshot returns the buffered records, oldest first
+func (r *recordRing) snapshot() []LogRecord {
+	records := make([]LogRecord, 0, r.count)
+	for i := 0; i < r.count; i++ {
+		records = append(records, r.entries[(r.start+i)%len(r.entries)])
+	}
+	return records
+}
+
+// TailRing writes the records held by the ring buffer to w as plain text.
+// With follow, new records keep being written to w from another goroutine
+// until the returned function is called; a follower that falls behind
+// misses lines instead of slowing down logging. The returned function
+// waits for the pending writes and is a no-op without follow.
+func (l *QLogger) TailRing(w io.Writer, follow bool) func() {
+	l.mu.Lock()
+	r := l.recordRing()
+	records := r.snapshot()
+	var tail chan LogRecord
+	if follow {
+		tail = make(chan LogRecord, tailBuffer)
+		r.tails[tail] = struct{}{}
+	}
+	l.mu.Unlock()
+
+	for _, record := range records {
+		writeText(w, record)
+	}
+	if !follow {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for record := range tail {
+			writeText(w, record)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			delete(r.tails, tail)
+			close(tail)
+			l.mu.Unlock()
+			<-done
+		})
+	}
+}
+
+// SetRingBuffer 在内存中保留最近 size 条日志，供 TailRing 查看
+func SetRingBuffer(size int) {
+	log.SetRingBuffer(size)
+}
+
+// TailRing 输出内存中保留的日志，follow 为 true 时持续输出新的日志直到调用返回的函数
+func TailRing(w io.Writer, follow bool) func() {
+	return log.TailRing(w, follow)
+}
@@ -0,0 +1,56 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestTailRing(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	l.SetRingBuffer(3)
+	for _, msg := range []string{"one", "two", "three", "four", "five"} {
+		l.Error("%s", msg)
+	}
+
+	var snapshot bytes.Buffer
+	l.TailRing(&snapshot, false)()
+	lines := strings.Split(strings.TrimSpace(snapshot.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "three") || !strings.HasSuffix(lines[2], "five") {
+		t.Errorf("snapshot = %q", lines)
+	}
+
+	var follower syncBuffer
+	stop := l.TailRing(&follower, true)
+	l.Error("six")
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(follower.String(), "six") && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	l.Error("seven")
+	got := follower.String()
+	if !strings.Contains(got, "three") || !strings.Contains(got, "six") || strings.Contains(got, "seven") {
+		t.Errorf("follower got %q", got)
+	}
+}
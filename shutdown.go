@@ -0,0 +1,15 @@
+package log
+
+// SetShuttingDown tags every subsequent record with shutdown=true while on,
+// so errors expected during a graceful shutdown can be told apart from real
+// ones
+func (l *QLogger) SetShuttingDown(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.shuttingDown = on
+}
+
+// SetShuttingDown 标记进程正在退出，之后的日志都会带上 shutdown=true 字段
+func SetShuttingDown(on bool) {
+	log.SetShuttingDown(on)
+}
@@ -0,0 +1,23 @@
+package log
+
+import "testing"
+
+func TestSetShuttingDown(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+
+	l.Error("before")
+	if _, ok := last.Fields["shutdown"]; ok {
+		t.Errorf("shutdown field before shutdown: %v", last.Fields)
+	}
+	l.SetShuttingDown(true)
+	l.Error("during")
+	if last.Fields["shutdown"] != true {
+		t.Errorf("fields during shutdown = %v", last.Fields)
+	}
+	l.SetShuttingDown(false)
+	l.Error("after")
+	if _, ok := last.Fields["shutdown"]; ok {
+		t.Errorf("shutdown field after turning it off: %v", last.Fields)
+	}
+}
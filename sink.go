@@ -0,0 +1,427 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kermitbu/gant-log/colors"
+)
+
+// recordWriter is implemented by outputs that consume the record fields and
+// the numeric level directly instead of the rendered template line.
+type recordWriter interface {
+	writeRecord(level int, record LogRecord) error
+}
+
+// sink is one output destination with its own rendering settings
+type sink struct {
+	name    string
+	writer  io.Writer
+	output  io.Writer
+	records recordWriter
+	format  Format
+	tty     bool
+	level   int
+	color   ColorMode
+	filter  func(LogRecord) bool
+
+	singleLine bool
+}
+
+// ColorMode selects whether an output gets colored text
+type ColorMode int
+
+const (
+	// ColorAlways colors the text regardless of the output
+	ColorAlways ColorMode = iota
+	// ColorAuto colors the text when the output is a terminal
+	ColorAuto
+	// ColorNever never colors the text and skips the color processing
+	// entirely, which is cheaper for high volume file outputs
+	ColorNever
+)
+
+// OutputOption configures an output added with AddOutput
+type OutputOption func(*sink)
+
+// WithFormat sets how records are rendered on the output
+func WithFormat(f Format) OutputOption {
+	return func(s *sink) {
+		s.format = f
+	}
+}
+
+// WithColor sets whether the text written to the output is colored
+func WithColor(mode ColorMode) OutputOption {
+	return func(s *sink) {
+		s.color = mode
+		s.setWriter(s.writer)
+	}
+}
+
+// WithLevel sets the minimum level of records written to the output
+func WithLevel(level int) OutputOption {
+	getLevelTag(level)
+	return func(s *sink) {
+		s.level = level
+	}
+}
+
+// WithFilter only writes the records for which keep returns true to the
+// output, e.g. the records of an "audit" component
+func WithFilter(keep func(LogRecord) bool) OutputOption {
+	return func(s *sink) {
+		s.filter = keep
+	}
+}
+
+// WithSingleLine folds multi-line messages into one physical line on the
+// output, see SetLineSeparator
+func WithSingleLine(on bool) OutputOption {
+	return func(s *sink) {
+		s.singleLine = on
+	}
+}
+
+func newSink(w io.Writer, opts ...OutputOption) *sink {
+	s := &sink{}
+	s.setWriter(w)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *sink) setWriter(w io.Writer) {
+	s.writer = w
+	s.records, _ = w.(recordWriter)
+	s.tty = isTerminal(w)
+	s.output = w
+	if w != nil && s.colored() {
+		s.output = colors.NewColorWriter(w)
+	}
+}
+
+// bind connects a sink created without a writer to os.Stdout
+func (s *sink) bind() {
+	if s.writer == nil {
+		s.setWriter(os.Stdout)
+	}
+}
+
+// colored reports whether text written to the sink gets color escapes
+func (s *sink) colored() bool {
+	switch s.color {
+	case ColorNever:
+		return false
+	case ColorAuto:
+		return s.tty
+	default:
+		return true
+	}
+}
+
+// effectiveFormat resolves FormatAuto against the sink writer
+func (s *sink) effectiveFormat() Format {
+	if s.format != FormatAuto {
+		return s.format
+	}
+	if s.tty {
+		return FormatText
+	}
+	return FormatJSON
+}
+
+// accepts reports whether the record passes the sink level and filter
+func (s *sink) accepts(level int, record LogRecord) bool {
+	return level >= s.level && (s.filter == nil || s.filter(record))
+}
+
+func (s *sink) write(level int, record LogRecord) error {
+	s.bind()
+	if s.records != nil {
+		return s.records.writeRecord(level, record)
+	}
+	switch s.effectiveFormat() {
+	case FormatJSON:
+		return writeJSON(s.output, level, record)
+	case FormatTable:
+		return writeTable(s.output, level, record, s.colored())
+	}
+	if s.colored() {
+		record.Level = levelColor(level)(record.Level)
+	}
+	return writeText(s.output, record)
+}
+
+// writeText renders the record with the text template. The template writes
+// a line in many pieces, so it is rendered first and w gets every record in
+// a single Write.
+func writeText(w io.Writer, record LogRecord) error {
+	var buf bytes.Buffer
+	if err := logRecordTemplate.Execute(&buf, record); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// AddOutput adds an output that receives every record in addition to the
+// existing ones
+func (l *QLogger) AddOutput(w io.Writer, opts ...OutputOption) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sinks = append(l.sinks, newSink(w, opts...))
+}
+
+// errDuplicateOutput is returned by AddNamedOutput in strict mode
+var errDuplicateOutput = errors.New("logger: duplicate output name")
+
+// AddNamedOutput adds an output that can be referred to by name. Adding an
+// output with the name of an existing one replaces it, closing the old
+// writer if the logger opened it, and logs a DEBUG note. With
+// SetStrictOutputNames the duplicate is rejected with an error instead.
+func (l *QLogger) AddNamedOutput(name string, w io.Writer, opts ...OutputOption) error {
+	l.mu.Lock()
+	s := newSink(w, opts...)
+	s.name = name
+
+	replaced := false
+	for i, old := range l.sinks {
+		if old.name != name || old == l.primary {
+			continue
+		}
+		if l.strictOutputNames {
+			l.mu.Unlock()
+			return fmt.Errorf("%w: %q", errDuplicateOutput, name)
+		}
+		l.closeOwned(old.writer)
+		l.sinks[i] = s
+		replaced = true
+		break
+	}
+	if !replaced {
+		l.sinks = append(l.sinks, s)
+	}
+	l.mu.Unlock()
+
+	if replaced {
+		l.mustLog(LevelDebug, 2, "output %q replaced", name)
+	}
+	return nil
+}
+
+// SetStrictOutputNames makes AddNamedOutput return an error for a name that
+// is already registered instead of replacing the output
+func (l *QLogger) SetStrictOutputNames(strict bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.strictOutputNames = strict
+}
+
+// closeOwned closes w and forgets it if the logger opened it. It must be
+// called with l.mu held.
+func (l *QLogger) closeOwned(w io.Writer) {
+	if !l.owns(w) {
+		return
+	}
+	owned := l.owned[:0]
+	for _, c := range l.owned {
+		if c == w.(io.Closer) {
+			c.Close()
+			continue
+		}
+		owned = append(owned, c)
+	}
+	l.owned = owned
+}
+
+// SetColorMode sets whether the text written to the primary output is
+// colored
+func (l *QLogger) SetColorMode(mode ColorMode) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.primary.color = mode
+	l.primary.setWriter(l.primary.writer)
+}
+
+// SetSingleLine folds multi-line messages into one physical line on the
+// primary output, so aggregators that split on newlines keep the record whole
+func (l *QLogger) SetSingleLine(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.primary.singleLine = on
+}
+
+// SetLineSeparator sets what replaces newlines in messages on single-line
+// outputs. The default is a literal \n.
+func (l *QLogger) SetLineSeparator(sep string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lineSeparator = sep
+}
+
+// SetContinuationIndent prefixes every line after the first of a
+// multi-line message with indent on outputs that keep newlines, so stack
+// traces line up under the record header
+func (l *QLogger) SetContinuationIndent(indent string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.continuationIndent = indent
+}
+
+// SetMessageEncoder sets a function encoding the message right before it is
+// written, e.g. base64 for binary safe transport. It runs last, after every
+// transformation of the message content such as line folding, and only
+// affects the outputs: hooks receive the message unencoded. nil removes the
+// encoder.
+func (l *QLogger) SetMessageEncoder(encode func(string) string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messageEncoder = encode
+}
+
+// foldLines replaces the newlines in message. It must be called with l.mu
+// held.
+func (l *QLogger) foldLines(message string) string {
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	return strings.ReplaceAll(message, "\n", l.lineSeparator)
+}
+
+// SetFileWithJSONSidecar writes the text format to path and the same
+// records as JSON to path.json. Both files are closed by Close. The color
+// mode becomes ColorAuto, so the text file gets no color escapes.
+func (l *QLogger) SetFileWithJSONSidecar(path string) error {
+	text, err := NewFileWriter(path)
+	if err != nil {
+		return err
+	}
+	sidecar, err := NewFileWriter(path + ".json")
+	if err != nil {
+		text.Close()
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.primary.color = ColorAuto
+	l.primary.setWriter(text)
+	l.primary.format = FormatText
+	l.sinks = append(l.sinks, newSink(sidecar, WithFormat(FormatJSON)))
+	l.owned = append(l.owned, text, sidecar)
+	return nil
+}
+
+// SetFileWithConsoleMirror writes every record to the file at path and
+// mirrors the records at or above consoleMinLevel to os.Stdout. The file is
+// closed by Close. The color mode becomes ColorAuto, so the file gets no
+// color escapes.
+func (l *QLogger) SetFileWithConsoleMirror(path string, consoleMinLevel int) error {
+	if consoleMinLevel < LevelDebug || consoleMinLevel > LevelFatal {
+		return fmt.Errorf("%w: %d", errInvalidLogLevel, consoleMinLevel)
+	}
+	file, err := NewFileWriter(path)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.primary.color = ColorAuto
+	l.primary.setWriter(file)
+	l.sinks = append(l.sinks, newSink(os.Stdout, WithLevel(consoleMinLevel)))
+	l.owned = append(l.owned, file)
+	return nil
+}
+
+// Close stops the async hook workers after the queued records were handled,
+// reports pending drops when drop reports were enabled, reports goroutine
+// leaks if WarnOnGoroutineLeak was called, logs the SetCloseSummary line and
+// closes the outputs opened by the logger. Those outputs are removed and
+// the primary output falls back to os.Stdout if it was one of them.
+func (l *QLogger) Close() error {
+	l.SetAsyncHooks(0, 0, OverflowDrop)
+
+	l.mu.Lock()
+	reporting := l.dropReport != nil
+	l.mu.Unlock()
+	if reporting {
+		l.SetDropReportInterval(0)
+		l.reportDrops()
+	}
+	l.checkGoroutineLeak()
+	l.logCloseSummary()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var err error
+	for _, c := range l.owned {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	sinks := l.sinks[:0]
+	for _, s := range l.sinks {
+		if s == l.primary || !l.owns(s.writer) {
+			sinks = append(sinks, s)
+		}
+	}
+	l.sinks = sinks
+	if l.owns(l.primary.writer) {
+		l.primary.setWriter(os.Stdout)
+	}
+	l.owned = nil
+	return err
+}
+
+// owns reports whether w was opened by the logger
+func (l *QLogger) owns(w io.Writer) bool {
+	c, ok := w.(io.Closer)
+	if !ok {
+		return false
+	}
+	for _, o := range l.owned {
+		if o == c {
+			return true
+		}
+	}
+	return false
+}
+
+// write renders the record to every output. It must be called with l.mu
+// held.
+func (l *QLogger) write(level int, record LogRecord) error {
+	var err error
+	for _, s := range l.sinks {
+		if !s.accepts(level, record) {
+			continue
+		}
+		r := record
+		if s.singleLine {
+			r.Message = l.foldLines(r.Message)
+			r.Stack = l.foldLines(r.Stack)
+		} else if l.continuationIndent != "" {
+			r.Message = strings.ReplaceAll(r.Message, "\n", "\n"+l.continuationIndent)
+			if r.Stack != "" {
+				r.Stack = l.continuationIndent + strings.ReplaceAll(r.Stack, "\n", "\n"+l.continuationIndent)
+			}
+		}
+		if l.messageEncoder != nil {
+			r.Message = l.messageEncoder(r.Message)
+		}
+		if werr := s.write(level, r); werr != nil && err == nil {
+			err = werr
+		}
+		if level >= l.alertLevel && s.records == nil && s.effectiveFormat() == FormatText {
+			if werr := writeAlertBox(s.output, level, r, s.colored()); werr != nil && err == nil {
+				err = werr
+			}
+		}
+	}
+	return err
+}
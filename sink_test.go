@@ -0,0 +1,250 @@
+package log
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kermitbu/gant-log/colors"
+)
+
+func TestSetFileWithJSONSidecar(t *testing.T) {
+	l := New()
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := l.SetFileWithJSONSidecar(path); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("disk %s", "slow")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	text := readFile(t, path)
+	if !strings.Contains(text, "disk slow") || strings.Contains(text, "\x1b[") {
+		t.Errorf("text file = %q", text)
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(readFile(t, path+".json")), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj["msg"] != "disk slow" {
+		t.Errorf("sidecar msg = %v", obj["msg"])
+	}
+}
+
+func TestSetFileWithConsoleMirror(t *testing.T) {
+	setLevel(t, LevelInfo)
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	prev := os.Stdout
+	os.Stdout = stdout
+	t.Cleanup(func() { os.Stdout = prev })
+
+	l := New()
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := l.SetFileWithConsoleMirror(path, LevelWarn); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("to the file")
+	l.Error("to both")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file := readFile(t, path)
+	if !strings.Contains(file, "to the file") || !strings.Contains(file, "to both") || strings.Contains(file, "\x1b[") {
+		t.Errorf("file = %q", file)
+	}
+	console := readFile(t, stdout.Name())
+	if strings.Contains(console, "to the file") || !strings.Contains(console, "to both") {
+		t.Errorf("console = %q", console)
+	}
+}
+
+func TestSetFileWithConsoleMirrorInvalidLevel(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	err := l.SetFileWithConsoleMirror(path, LevelFatal+1)
+	if !errors.Is(err, errInvalidLogLevel) {
+		t.Fatalf("err = %v, want errInvalidLogLevel", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file opened for an invalid level: %v", err)
+	}
+	if len(l.sinks) != 1 || len(l.owned) != 0 {
+		t.Errorf("outputs changed: %d sinks, %d owned", len(l.sinks), len(l.owned))
+	}
+}
+
+func TestSingleLinePerOutput(t *testing.T) {
+	l, console := newBufferLogger(t)
+	var aggregator bytes.Buffer
+	l.AddOutput(&aggregator, WithSingleLine(true))
+	l.SetLineSeparator("⏎")
+
+	l.Error("first\r\nsecond")
+	if got := aggregator.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "first⏎second") {
+		t.Errorf("single-line output = %q", got)
+	}
+	if got := console.String(); !strings.Contains(got, "first\r\nsecond") {
+		t.Errorf("console output = %q", got)
+	}
+
+	console.Reset()
+	l.SetSingleLine(true)
+	l.SetLineSeparator(`\n`)
+	l.Error("first\nsecond")
+	if got := console.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, `first\nsecond`) {
+		t.Errorf("primary single-line output = %q", got)
+	}
+}
+
+func TestContinuationIndent(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.SetContinuationIndent("    ")
+	l.Error("first\nsecond\nthird")
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "first") || lines[1] != "    second" || lines[2] != "    third" {
+		t.Errorf("lines = %q", lines)
+	}
+}
+
+func TestOutputFilter(t *testing.T) {
+	l, all := newBufferLogger(t)
+	var audit bytes.Buffer
+	l.AddOutput(&audit, WithColor(ColorNever), WithFilter(func(r LogRecord) bool {
+		return r.Fields["audit"] == true
+	}))
+
+	l.Error("regular")
+	l.Errorw("login by bob", OrderedFields{{Key: "audit", Value: true}})
+
+	if got := audit.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "login by bob") {
+		t.Errorf("audit output = %q", got)
+	}
+	if got := all.String(); strings.Count(got, "\n") != 2 {
+		t.Errorf("primary output = %q", got)
+	}
+}
+
+func TestAddNamedOutputReplaces(t *testing.T) {
+	setLevel(t, LevelDebug)
+	l, primary := newBufferLogger(t)
+	var first, second bytes.Buffer
+	if err := l.AddNamedOutput("file", &first, WithColor(ColorNever)); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddNamedOutput("file", &second, WithColor(ColorNever)); err != nil {
+		t.Fatal(err)
+	}
+	l.Error("after")
+
+	if strings.Contains(first.String(), "after") {
+		t.Errorf("replaced output still written: %q", first.String())
+	}
+	if !strings.Contains(second.String(), "after") {
+		t.Errorf("new output = %q", second.String())
+	}
+	if !strings.Contains(primary.String(), `output "file" replaced`) {
+		t.Errorf("no replacement note: %q", primary.String())
+	}
+}
+
+func TestAddNamedOutputStrict(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	l.SetStrictOutputNames(true)
+	var first, second bytes.Buffer
+	if err := l.AddNamedOutput("file", &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AddNamedOutput("file", &second); !errors.Is(err, errDuplicateOutput) {
+		t.Errorf("duplicate name error = %v", err)
+	}
+	l.Error("kept")
+	if !strings.Contains(first.String(), "kept") || second.Len() != 0 {
+		t.Errorf("first = %q, second = %q", first.String(), second.String())
+	}
+}
+
+// BenchmarkColorNeverShortCircuit compares a ColorNever sink with the
+// short-circuit against the path it replaced, where every record still went
+// through the level color function and the output through the color writer
+func BenchmarkColorNeverShortCircuit(b *testing.B) {
+	record := LogRecord{
+		Time:    time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		Level:   getLevelTag(LevelError),
+		Caller:  "handler.go:42",
+		Message: "request 17 done",
+	}
+	b.Run("short-circuit", func(b *testing.B) {
+		s := newSink(io.Discard, WithColor(ColorNever))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := s.write(LevelError, record); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("color-path", func(b *testing.B) {
+		output := colors.NewColorWriter(io.Discard)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r := record
+			r.Level = levelColor(LevelError)(r.Level)
+			if err := writeText(output, r); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestColorNeverSkipsColorWriter(t *testing.T) {
+	var buf bytes.Buffer
+	s := newSink(&buf, WithColor(ColorNever))
+	if s.output != io.Writer(&buf) {
+		t.Errorf("ColorNever output wrapped in %T", s.output)
+	}
+	if err := s.write(LevelError, LogRecord{Level: getLevelTag(LevelError), Message: "plain"}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Errorf("ColorNever line colored: %q", buf.String())
+	}
+}
+
+func TestMessageEncoder(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	last := lastRecord(l)
+	l.SetSingleLine(true)
+	l.SetMessageEncoder(func(m string) string {
+		return base64.StdEncoding.EncodeToString([]byte(m))
+	})
+
+	l.Error("a\nb")
+	// Folding runs before encoding
+	want := base64.StdEncoding.EncodeToString([]byte(`a\nb`))
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), want) {
+		t.Errorf("line = %q, want the message encoded as %q", buf.String(), want)
+	}
+	if last.Message != "a\nb" {
+		t.Errorf("hook message = %q, want it unencoded", last.Message)
+	}
+
+	buf.Reset()
+	l.SetMessageEncoder(nil)
+	l.Error("plain")
+	if !strings.HasSuffix(strings.TrimSpace(buf.String()), "plain") {
+		t.Errorf("line after removing the encoder = %q", buf.String())
+	}
+}
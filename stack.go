@@ -0,0 +1,36 @@
+package log
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// SetAutoStackLevel attaches the stack of the calling goroutine to every
+// record at or above level, e.g. LevelError. LevelFatal+1 turns it off,
+// which is the default.
+func (l *QLogger) SetAutoStackLevel(level int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.autoStackLevel = level
+}
+
+// callerStack formats the stack starting skip frames above its caller
+func callerStack(skip int) string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+2, pcs)])
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// SetAutoStackLevel 设置自动附加调用堆栈的最低日志级别，例如 LevelError
+func SetAutoStackLevel(level int) {
+	log.SetAutoStackLevel(level)
+}
@@ -0,0 +1,28 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAutoStackLevel(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+	l.SetAutoStackLevel(LevelError)
+
+	l.Info("no stack")
+	if last.Stack != "" {
+		t.Errorf("INFO record has a stack:\n%s", last.Stack)
+	}
+	l.Error("with stack")
+	if !strings.HasPrefix(last.Stack, "github.com/kermitbu/gant-log.TestAutoStackLevel\n") {
+		t.Errorf("ERROR stack does not start at the caller:\n%s", last.Stack)
+	}
+
+	l.SetAutoStackLevel(LevelFatal + 1)
+	l.Error("off again")
+	if last.Stack != "" {
+		t.Errorf("stack attached while off:\n%s", last.Stack)
+	}
+}
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetEmitConfigOnStart logs one INFO line describing the effective level,
+// format and outputs right before the first record, so every log file
+// documents how it was produced
+func (l *QLogger) SetEmitConfigOnStart(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.emitConfig = on
+}
+
+// emitConfigLine writes the configuration line once. It must be called with
+// l.mu held.
+func (l *QLogger) emitConfigLine(now time.Time) {
+	if !l.emitConfig || l.configEmitted {
+		return
+	}
+	l.configEmitted = true
+
+	outputs := make([]string, 0, len(l.sinks))
+	for _, s := range l.sinks {
+		s.bind()
+		name := fmt.Sprintf("%T", s.writer)
+		if s.name != "" {
+			name = s.name
+		}
+		outputs = append(outputs, fmt.Sprintf("%s(%s,%s)", name, s.effectiveFormat(), levelName(s.level)))
+	}
+	l.emit(LevelInfo, LogRecord{
+		Time:    now,
+		Level:   getLevelTag(LevelInfo),
+		Message: "logger configuration",
+		Fields: map[string]interface{}{
+			"min_level": levelName(GetLevel()),
+			"format":    l.primary.effectiveFormat().String(),
+			"outputs":   strings.Join(outputs, " "),
+		},
+	})
+}
+
+// SetEmitConfigOnStart 开启后在第一条日志之前输出一条 INFO 日志，记录当前的级别、格式和输出目标
+func SetEmitConfigOnStart(on bool) {
+	log.SetEmitConfigOnStart(on)
+}
@@ -0,0 +1,46 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestEmitConfigOnStart(t *testing.T) {
+	setLevel(t, LevelWarn)
+	l, buf := newBufferLogger(t)
+	l.SetFormat(FormatJSON)
+	l.SetEmitConfigOnStart(true)
+	for i := 0; i < 3; i++ {
+		l.Warn("record %d", i)
+	}
+
+	var configs []map[string]interface{}
+	lines := 0
+	scanner := bufio.NewScanner(bytes.NewReader(buf.Bytes()))
+	for scanner.Scan() {
+		lines++
+		var obj map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &obj); err != nil {
+			t.Fatal(err)
+		}
+		if obj["msg"] == "logger configuration" {
+			if lines != 1 {
+				t.Errorf("configuration logged as line %d, want 1", lines)
+			}
+			configs = append(configs, obj)
+		}
+	}
+	if len(configs) != 1 || lines != 4 {
+		t.Fatalf("got %d configuration lines in %d lines, want 1 in 4", len(configs), lines)
+	}
+	c := configs[0]
+	if c["level"] != "INFO" || c["min_level"] != "WARN" || c["format"] != "json" {
+		t.Errorf("configuration = %v", c)
+	}
+	if outputs, _ := c["outputs"].(string); !strings.Contains(outputs, "*bytes.Buffer(json,") {
+		t.Errorf("outputs = %q", outputs)
+	}
+}
@@ -0,0 +1,44 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetCloseSummary makes Close log a one line summary of the records written
+// by level, the dropped records and the uptime, e.g.
+// "logger shutdown: debug=0 info=1042 warn=12 error=3 fatal=0 dropped=0 uptime=2h3m"
+func (l *QLogger) SetCloseSummary(on bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closeSummary = on
+}
+
+// logCloseSummary logs the summary if enabled. It must be called without
+// l.mu held.
+func (l *QLogger) logCloseSummary() {
+	l.mu.Lock()
+	if !l.closeSummary {
+		l.mu.Unlock()
+		return
+	}
+	counts := make([]string, 0, len(l.levelCounts))
+	for level, n := range l.levelCounts {
+		counts = append(counts, fmt.Sprintf("%s=%d", strings.ToLower(levelName(level)), n))
+	}
+	uptime := time.Since(l.started)
+	l.mu.Unlock()
+
+	var dropped uint64
+	for _, n := range drops.snapshot() {
+		dropped += n
+	}
+	l.mustLog(LevelInfo, 3, "logger shutdown: %s dropped=%d uptime=%s",
+		strings.Join(counts, " "), dropped, FormatDuration(uptime))
+}
+
+// SetCloseSummary 开启后 Close 时输出一行各级别日志数量、丢弃数量和运行时长的汇总
+func SetCloseSummary(on bool) {
+	log.SetCloseSummary(on)
+}
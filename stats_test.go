@@ -0,0 +1,34 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestCloseSummary(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, buf := newBufferLogger(t)
+	l.SetCloseSummary(true)
+
+	l.Debug("filtered")
+	l.Info("one")
+	l.Info("two")
+	l.Warn("three")
+	l.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := regexp.MustCompile(`logger shutdown: debug=0 info=2 warn=1 error=0 fatal=0 dropped=\d+ uptime=\S+$`)
+	if last := lines[len(lines)-1]; !want.MatchString(last) {
+		t.Errorf("summary = %q", last)
+	}
+}
+
+func TestCloseSummaryOff(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.Error("one")
+	l.Close()
+	if strings.Contains(buf.String(), "logger shutdown") {
+		t.Errorf("summary logged while off: %q", buf.String())
+	}
+}
@@ -0,0 +1,40 @@
+package log
+
+import "fmt"
+
+// SetStrictFormat enables a warning whenever a message containing format
+// verbs is logged without any arguments, which usually means the arguments
+// were forgotten
+func (l *QLogger) SetStrictFormat(strict bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.strictFormat = strict
+}
+
+// hasFormatVerb reports whether format contains a verb other than %%
+func hasFormatVerb(format string) bool {
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// warnMissingArgs writes a WARN record pointing at the call site of record.
+// It must be called with l.mu held.
+func (l *QLogger) warnMissingArgs(record LogRecord, format string) {
+	if !levelEnabled(record.Component, LevelWarn) {
+		return
+	}
+	warning := record
+	warning.Level = getLevelTag(LevelWarn)
+	warning.Message = fmt.Sprintf("format %q has verbs but no arguments at %s:%d",
+		format, record.Filename, record.LineNo)
+	l.emit(LevelWarn, warning)
+}
@@ -0,0 +1,57 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+// errorNoArgs logs format without arguments, which vet rejects at a call
+// of Info
+func infoNoArgs(l *QLogger, format string) {
+	var none []interface{}
+	l.mustLog(LevelInfo, 2, format, none...)
+}
+
+func TestStrictFormat(t *testing.T) {
+	setLevel(t, LevelInfo)
+	l, buf := newBufferLogger(t)
+	infoNoArgs(l, "value: %s")
+	if strings.Contains(buf.String(), "has verbs") {
+		t.Fatalf("warned without strict mode: %q", buf.String())
+	}
+
+	l.SetStrictFormat(true)
+	buf.Reset()
+	infoNoArgs(l, "value: %s")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want the record and a warning: %q", len(lines), lines)
+	}
+	if !strings.Contains(lines[0], "value: ") {
+		t.Errorf("record = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], getLevelTag(LevelWarn)) || !strings.Contains(lines[1], `format "value: %s" has verbs but no arguments at strict_test.go:`) {
+		t.Errorf("warning = %q", lines[1])
+	}
+
+	buf.Reset()
+	l.Error("done 100%%")
+	l.Error("took %d ms", 5)
+	if strings.Contains(buf.String(), "has verbs") {
+		t.Errorf("warned for a format without missing arguments: %q", buf.String())
+	}
+}
+
+func TestHasFormatVerb(t *testing.T) {
+	for format, want := range map[string]bool{
+		"plain":     false,
+		"100%%":     false,
+		"%d items":  true,
+		"50%% %s":   true,
+		"trailing%": true,
+	} {
+		if got := hasFormatVerb(format); got != want {
+			t.Errorf("hasFormatVerb(%q) = %t, want %t", format, got, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// tableColumns are the column widths of FormatTable
+type tableColumns struct {
+	time, level, caller int
+}
+
+const (
+	defaultTableLevelWidth  = 5
+	defaultTableCallerWidth = 24
+	tableSeparator          = " | "
+)
+
+// tableWidths holds the tableColumns installed by SetTableColumns
+var tableWidths atomic.Value
+
+// SetTableColumns sets the widths of the time, level and caller columns of
+// FormatTable, in terminal cells. Shorter content is padded and a longer
+// caller is cut at the start with an ellipsis, keeping the line number.
+// Zero selects the default: the width of the time layout, 5 and 24.
+func SetTableColumns(time, level, caller int) {
+	tableWidths.Store(tableColumns{time: time, level: level, caller: caller})
+}
+
+func currentTableColumns() tableColumns {
+	c, _ := tableWidths.Load().(tableColumns)
+	if c.time <= 0 {
+		c.time = stringWidth(timeLayout())
+	}
+	if c.level <= 0 {
+		c.level = defaultTableLevelWidth
+	}
+	if c.caller <= 0 {
+		c.caller = defaultTableCallerWidth
+	}
+	return c
+}
+
+// writeTable writes the record as one row of aligned columns: time, level,
+// caller and the message with its fields. Continuation lines of the message
+// and the stack are indented to the message column.
+func writeTable(w io.Writer, level int, record LogRecord, color bool) error {
+	c := currentTableColumns()
+	var buf bytes.Buffer
+	buf.WriteString(padCell(stamp(record.Time), c.time))
+	buf.WriteString(tableSeparator)
+	levelCell := padCell(strings.TrimSpace(record.Level), c.level)
+	if color {
+		levelCell = levelColor(level)(levelCell)
+	}
+	buf.WriteString(levelCell)
+	buf.WriteString(tableSeparator)
+	buf.WriteString(padCell(truncateLeft(record.Caller, c.caller), c.caller))
+	buf.WriteString(tableSeparator)
+
+	indent := "\n" + strings.Repeat(" ", c.time+c.level+c.caller+3*len(tableSeparator))
+	text := record.Message + formatFields(record)
+	if record.Stack != "" {
+		text += "\n" + record.Stack
+	}
+	buf.WriteString(strings.ReplaceAll(strings.TrimRight(text, "\n"), "\n", indent))
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// padCell pads s with spaces to width cells
+func padCell(s string, width int) string {
+	if n := stringWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// truncateLeft cuts the start of s so it fits width cells, marking the cut
+// with an ellipsis
+func truncateLeft(s string, width int) string {
+	if stringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	n := 1 // the ellipsis
+	i := len(runes)
+	for i > 0 && n+runeWidth(runes[i-1]) <= width {
+		i--
+		n += runeWidth(runes[i])
+	}
+	return "…" + string(runes[i:])
+}
+
+// stringWidth returns the number of terminal cells s occupies
+func stringWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+// runeWidth returns the number of terminal cells r occupies: 0 for
+// combining and control characters, 2 for East Asian wide and fullwidth
+// characters and 1 otherwise
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || r == 0x7f:
+		return 0
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200b:
+		return 0
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// wideRanges are the East Asian wide and fullwidth ranges
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},
+	{0x2e80, 0x303e},
+	{0x3041, 0x33ff},
+	{0x3400, 0x4dbf},
+	{0x4e00, 0x9fff},
+	{0xa000, 0xa4cf},
+	{0xac00, 0xd7a3},
+	{0xf900, 0xfaff},
+	{0xfe30, 0xfe4f},
+	{0xff00, 0xff60},
+	{0xffe0, 0xffe6},
+	{0x1f300, 0x1f64f},
+	{0x1f900, 0x1f9ff},
+	{0x20000, 0x2fffd},
+	{0x30000, 0x3fffd},
+}
+
+func isWide(r rune) bool {
+	for _, rg := range wideRanges {
+		if r >= rg[0] && r <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
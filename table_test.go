@@ -0,0 +1,61 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatTable(t *testing.T) {
+	SetTableColumns(0, 0, 12)
+	t.Cleanup(func() { SetTableColumns(0, 0, 0) })
+	at := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+
+	var buf bytes.Buffer
+	records := []LogRecord{
+		{Time: at, Level: getLevelTag(LevelError), Caller: "a.go:1", Message: "short"},
+		{Time: at, Level: getLevelTag(LevelInfo), Caller: "internal/service/handler.go:1234", Message: "日志 wide\nsecond line"},
+	}
+	for _, record := range records {
+		if err := writeTable(&buf, LevelInfo, record, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines = %q", lines)
+	}
+
+	// The message column starts at the same cell on every line
+	column := stringWidth(timeLayout()) + 5 + 12 + 3*len(tableSeparator)
+	for i, line := range lines {
+		if i == 2 {
+			if got := stringWidth(line[:len(line)-len("second line")]); got != column {
+				t.Errorf("continuation starts at cell %d, want %d: %q", got, column, line)
+			}
+			continue
+		}
+		message := records[i].Message
+		if j := strings.IndexByte(message, '\n'); j >= 0 {
+			message = message[:j]
+		}
+		if !strings.HasSuffix(line, message) {
+			t.Fatalf("line %q lacks %q", line, message)
+		}
+		if got := stringWidth(strings.TrimSuffix(line, message)); got != column {
+			t.Errorf("message starts at cell %d, want %d: %q", got, column, line)
+		}
+	}
+	if !strings.Contains(lines[1], "…ler.go:1234 | ") {
+		t.Errorf("long caller not cut at the start: %q", lines[1])
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	for s, want := range map[string]int{"abc": 3, "日志": 4, "é": 1, "a\tb": 2} {
+		if got := stringWidth(s); got != want {
+			t.Errorf("stringWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
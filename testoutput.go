@@ -0,0 +1,40 @@
+package log
+
+import "strings"
+
+// TB is the part of testing.TB used by SetTestOutput. It keeps the package
+// from importing testing, which would register the test flags in every
+// program using the logger.
+type TB interface {
+	Helper()
+	Log(args ...interface{})
+	Cleanup(func())
+}
+
+// testWriter forwards every log line to t.Log
+type testWriter struct {
+	t TB
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	// t.Log adds its own newline
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+// SetTestOutput routes the log lines to t.Log so they are attached to the
+// test and only shown when it fails or runs verbosely. The previous output
+// is restored when the test finishes.
+func (l *QLogger) SetTestOutput(t TB) {
+	prev := l.Output()
+	l.SetOutput(testWriter{t: t})
+	t.Cleanup(func() {
+		l.SetOutput(prev)
+	})
+}
+
+// SetTestOutput 将日志转发到 t.Log，测试结束后恢复原来的输出
+func SetTestOutput(t TB) {
+	log.SetTestOutput(t)
+}
@@ -0,0 +1,45 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+// recordingTB records the lines logged to it and the cleanups registered
+type recordingTB struct {
+	lines    []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Log(args ...interface{}) {
+	for _, a := range args {
+		r.lines = append(r.lines, a.(string))
+	}
+}
+
+func (r *recordingTB) Cleanup(fn func()) {
+	r.cleanups = append(r.cleanups, fn)
+}
+
+func TestSetTestOutput(t *testing.T) {
+	l, prev := newBufferLogger(t)
+	tb := &recordingTB{}
+	l.SetTestOutput(tb)
+	l.Error("attached")
+	if len(tb.lines) != 1 || !strings.HasSuffix(tb.lines[0], "attached") {
+		t.Errorf("test lines = %q", tb.lines)
+	}
+	if prev.Len() != 0 {
+		t.Errorf("line also written to the previous output: %q", prev.String())
+	}
+
+	for _, fn := range tb.cleanups {
+		fn()
+	}
+	l.Error("restored")
+	if !strings.Contains(prev.String(), "restored") || len(tb.lines) != 1 {
+		t.Errorf("output not restored: %q", prev.String())
+	}
+}
@@ -0,0 +1,55 @@
+package log
+
+import (
+	"sync/atomic"
+
+	"github.com/kermitbu/gant-log/colors"
+)
+
+// Theme selects the colors of the level tags
+type Theme int32
+
+const (
+	// ThemeDefault gives every level its own bold color
+	ThemeDefault Theme = iota
+	// ThemeIntensity makes higher levels visually heavier: dim DEBUG,
+	// plain INFO, bold WARN and bright bold ERROR and FATAL
+	ThemeIntensity
+)
+
+var theme int32
+
+// SetTheme sets the colors of the level tags on colored outputs
+func SetTheme(t Theme) {
+	atomic.StoreInt32(&theme, int32(t))
+}
+
+func currentTheme() Theme {
+	return Theme(atomic.LoadInt32(&theme))
+}
+
+// intensityColor returns the ThemeIntensity color function of level
+func intensityColor(level int) func(string) string {
+	switch level {
+	case LevelDebug:
+		return colors.DarkGray
+	case LevelInfo:
+		return colors.Green
+	case LevelWarn:
+		return colors.YellowBold
+	case LevelError:
+		return colors.LightRedBold
+	case LevelFatal:
+		return colors.LightMagentaBold
+	default:
+		panic(errInvalidLogLevel)
+	}
+}
+
+var intensityColorNames = [...]string{
+	LevelDebug: "darkgray",
+	LevelInfo:  "green",
+	LevelWarn:  "yellow+bold",
+	LevelError: "lightred+bold",
+	LevelFatal: "lightmagenta+bold",
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+// setTheme sets the theme for the duration of the test
+func setTheme(t *testing.T, theme Theme) {
+	t.Helper()
+	SetTheme(theme)
+	t.Cleanup(func() { SetTheme(ThemeDefault) })
+}
+
+func TestThemeIntensity(t *testing.T) {
+	setLevel(t, LevelDebug)
+	setTheme(t, ThemeIntensity)
+	l, buf := newBufferLogger(t)
+	l.SetColorMode(ColorAlways)
+
+	for level, want := range map[int]string{
+		LevelDebug: "\x1b[90m",
+		LevelInfo:  "\x1b[32m",
+		LevelWarn:  "\x1b[33m",
+		LevelError: "\x1b[91m",
+	} {
+		buf.Reset()
+		l.mustLog(level, 1, "intensity")
+		tag := strings.TrimSpace(getLevelTag(level))
+		if got := buf.String(); !strings.Contains(got, want+levelBold(level, tag)) {
+			t.Errorf("%s line = %q, want %q", tag, got, want)
+		}
+	}
+
+	// Only the levels from WARN up are bold
+	if strings.Contains(intensityColor(LevelInfo)("x"), "\x1b[1m") || !strings.Contains(intensityColor(LevelError)("x"), "\x1b[1m") {
+		t.Error("bold levels do not increase with severity")
+	}
+
+	buf.Reset()
+	l.printColorLegend()
+	if got := buf.String(); !strings.Contains(got, "=darkgray") || !strings.Contains(got, "=lightmagenta+bold") {
+		t.Errorf("legend = %q", got)
+	}
+}
+
+// levelBold returns tag as rendered inside the level color
+func levelBold(level int, tag string) string {
+	if level >= LevelWarn {
+		return "\x1b[1m" + tag
+	}
+	return tag
+}
@@ -0,0 +1,46 @@
+package log
+
+import "time"
+
+// Timer starts timing an operation and returns a function that logs its
+// duration at INFO when called, typically via defer
+func (l *QLogger) Timer(name string) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		fields := map[string]interface{}{
+			"elapsed_ms": elapsed.Milliseconds(),
+		}
+		l.mustLogWith(callOptions{fields: fields}, LevelInfo, 2, "%s took %s", name, FormatDuration(elapsed))
+	}
+}
+
+// TimerSLO is like Timer but also reports whether the operation met the
+// target duration. A missed target is logged at WARN with the overage.
+func (l *QLogger) TimerSLO(name string, target time.Duration) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		fields := map[string]interface{}{
+			"elapsed_ms": elapsed.Milliseconds(),
+			"target_ms":  target.Milliseconds(),
+			"slo_met":    elapsed <= target,
+		}
+		level := LevelInfo
+		if elapsed > target {
+			level = LevelWarn
+			fields["over_by_ms"] = (elapsed - target).Milliseconds()
+		}
+		l.mustLogWith(callOptions{fields: fields}, level, 2, "%s took %s", name, FormatDuration(elapsed))
+	}
+}
+
+// Timer 开始计时，调用返回的函数时输出操作耗时，通常配合 defer 使用
+func Timer(name string) func() {
+	return log.Timer(name)
+}
+
+// TimerSLO 与 Timer 相同，同时记录耗时是否满足目标 target，超时以 WARN 输出
+func TimerSLO(name string, target time.Duration) func() {
+	return log.TimerSLO(name, target)
+}
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimerSLO(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	last := lastRecord(l)
+	var level int
+	l.AddHook(func(lv int, record LogRecord) { level = lv })
+
+	stop := l.TimerSLO("query", 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	stop()
+
+	if level != LevelWarn {
+		t.Errorf("missed target logged at %d, want WARN", level)
+	}
+	if last.Fields["slo_met"] != false {
+		t.Errorf("slo_met = %v", last.Fields["slo_met"])
+	}
+	if over, _ := last.Fields["over_by_ms"].(int64); over < 25 {
+		t.Errorf("over_by_ms = %v, want at least 25", last.Fields["over_by_ms"])
+	}
+
+	stop = l.TimerSLO("query", time.Hour)
+	stop()
+	if level != LevelInfo || last.Fields["slo_met"] != true {
+		t.Errorf("met target logged at %d with %v", level, last.Fields)
+	}
+	if _, ok := last.Fields["over_by_ms"]; ok {
+		t.Error("over_by_ms logged for a met target")
+	}
+}